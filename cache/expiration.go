@@ -1,23 +1,79 @@
 package cache
 
 import (
+	"errors"
 	"runtime"
 	"sync"
 	"time"
 	"weak"
 )
 
+// ErrNotFound can be returned, or wrapped, by the Get callback to signal that the key does not exist.
+// When a negative TTL is set, with WithNegativeTTL, the absence is cached as well.
+var ErrNotFound = errors.New("not found")
+
+// Presence tells how a key is held by the cache
+type Presence int
+
+const (
+	// Absent means the key is not in the cache
+	Absent Presence = iota
+	// Present means the key has a cached value
+	Present
+	// NotFound means the key is known not to exist, as reported by a Get callback
+	NotFound
+)
+
+type ExpirationOption[K comparable, V any] func(*Expiration[K, V])
+
+// WithNegativeTTL caches the ErrNotFound errors returned by the Get callback for the duration of ttl.
+// Unlike values, negative entries are not extended when accessed.
+func WithNegativeTTL[K comparable, V any](ttl time.Duration) ExpirationOption[K, V] {
+	return func(c *Expiration[K, V]) {
+		c.negativeTTL = ttl
+	}
+}
+
+// WithExpirationMemoryPressure sheds the coldest entries when the memory in use exceeds the limit.
+// Unlike with WithLRUMemoryPressure, the memory is sampled every interval in the background,
+// so that entries are shed even when the cache is idle or only read.
+func WithExpirationMemoryPressure[K comparable, V any](mp MemoryPressure) ExpirationOption[K, V] {
+	return func(c *Expiration[K, V]) {
+		c.pressure = newMemoryMonitor(mp)
+	}
+}
+
 type Expiration[K comparable, V any] struct {
-	items   *LRU[K, *item[V]]
-	timeout time.Duration
-	quit    chan struct{}
-	mu      sync.Mutex
-	locks   map[K]*sync.Mutex
+	items       *LRU[K, *item[V]]
+	timeout     time.Duration
+	negativeTTL time.Duration
+	pressure    *memoryMonitor
+	quit        *quitSignal
+	mu          sync.Mutex
+	locks       map[K]*sync.Mutex
+}
+
+// quitSignal stops the cleanup goroutine. It can be closed both by Dispose and by the GC cleanup.
+type quitSignal struct {
+	once sync.Once
+	ch   chan struct{}
+}
+
+func (q *quitSignal) close() {
+	q.once.Do(func() {
+		close(q.ch)
+	})
 }
 
 type item[V any] struct {
 	value      V
 	expiration time.Time
+	// err is set for negative entries
+	err error
+}
+
+func (i *item[V]) negative() bool {
+	return i.err != nil
 }
 
 // Returns true if the item has expired.
@@ -25,22 +81,34 @@ func (i *item[V]) expired() bool {
 	return i.expiration.Before(time.Now())
 }
 
-func NewExpiration[K comparable, V any](capacity int, timeout time.Duration, interval time.Duration, onEvict func(key K, value V)) *Expiration[K, V] {
-	quit := make(chan struct{})
+// NewExpiration creates a cache where entries expire after not being accessed for the timeout duration.
+// Expired entries are removed every interval. onEvict is optional, and it is not called for negative entries.
+func NewExpiration[K comparable, V any](capacity int, timeout time.Duration, interval time.Duration, onEvict func(key K, value V), options ...ExpirationOption[K, V]) *Expiration[K, V] {
+	quit := &quitSignal{ch: make(chan struct{})}
+	var evict func(key K, value *item[V])
+	if onEvict != nil {
+		evict = func(key K, value *item[V]) {
+			if !value.negative() {
+				onEvict(key, value.value)
+			}
+		}
+	}
 	cache := &Expiration[K, V]{
-		items: NewLRU[K, *item[V]](capacity, func(key K, value *item[V]) {
-			onEvict(key, value.value)
-		}),
+		items:   NewLRU(capacity, evict),
 		timeout: timeout,
 		quit:    quit,
-		locks:   make(map[K]*sync.Mutex, capacity),
+		locks:   make(map[K]*sync.Mutex, max(capacity, 0)),
 	}
 
-	runtime.AddCleanup(cache, func(quit chan struct{}) {
-		close(quit)
+	for _, opt := range options {
+		opt(cache)
+	}
+
+	runtime.AddCleanup(cache, func(quit *quitSignal) {
+		quit.close()
 	}, quit)
 
-	go cleanup(weak.Make(cache), interval, quit)
+	go cleanup(weak.Make(cache), interval, cache.pressure, quit.ch)
 
 	return cache
 }
@@ -50,29 +118,49 @@ func (c *Expiration[K, V]) Dispose() {
 	defer c.mu.Unlock()
 
 	if c.quit != nil {
-		close(c.quit)
+		c.quit.close()
 		c.quit = nil
 		c.items.Clear()
 		c.locks = nil
 	}
 }
 
-func (c *Expiration[K, V]) GetIfPresent(key K) (V, bool) {
+// GetIfPresent returns the cached value of the key and whether the key is present, absent or known not to exist.
+// The value is only set when the key is Present.
+func (c *Expiration[K, V]) GetIfPresent(key K) (V, Presence) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	v, ok := c.items.Get(key)
-	if ok {
-		v.expiration = time.Now().Add(c.timeout)
-		return v.value, true
-	}
 	var zero V
-	return zero, false
+	it, ok := c.get(key)
+	if !ok {
+		return zero, Absent
+	}
+	if it.negative() {
+		return zero, NotFound
+	}
+	it.expiration = time.Now().Add(c.timeout)
+	return it.value, Present
 }
 
+// get returns the item of the key, discarding expired negative entries. The caller must hold the lock.
+func (c *Expiration[K, V]) get(key K) (*item[V], bool) {
+	it, ok := c.items.Get(key)
+	if !ok {
+		return nil, false
+	}
+	if it.negative() && it.expired() {
+		c.items.Delete(key)
+		return nil, false
+	}
+	return it, true
+}
+
+// Get returns the cached value of the key, calling callback to create it if it is not in the cache.
+// If the key was cached as not found, the error originally returned by callback is returned, without calling it again.
 func (c *Expiration[K, V]) Get(key K, callback func() (V, error)) (V, error) {
 	c.mu.Lock()
-	it, ok := c.items.Get(key)
+	it, ok := c.get(key)
 	c.mu.Unlock()
 
 	if !ok {
@@ -90,9 +178,12 @@ func (c *Expiration[K, V]) Get(key K, callback func() (V, error)) (V, error) {
 
 		// recheck if the item was created while waiting for the lock
 		c.mu.Lock()
-		it, ok = c.items.Get(key)
+		it, ok = c.get(key)
 		c.mu.Unlock()
 		if ok {
+			if it.negative() {
+				return *new(V), it.err
+			}
 			it.expiration = time.Now().Add(c.timeout)
 			return it.value, nil
 		}
@@ -100,13 +191,21 @@ func (c *Expiration[K, V]) Get(key K, callback func() (V, error)) (V, error) {
 		// create the item
 		v, err := callback()
 		if err != nil {
+			if c.negativeTTL > 0 && errors.Is(err, ErrNotFound) {
+				c.mu.Lock()
+				c.items.Put(key, &item[V]{err: err, expiration: time.Now().Add(c.negativeTTL)})
+				c.mu.Unlock()
+			}
 			return *new(V), err
 		}
-		it = &item[V]{v, time.Now().Add(c.timeout)}
+		it = &item[V]{value: v, expiration: time.Now().Add(c.timeout)}
 		c.mu.Lock()
 		c.items.Put(key, it)
 		c.mu.Unlock()
 	}
+	if it.negative() {
+		return *new(V), it.err
+	}
 	return it.value, nil
 }
 
@@ -114,16 +213,24 @@ func (c *Expiration[K, V]) Put(key K, value V) {
 	c.mu.Lock()
 	// defer now since I do not know what will happen in a out of memory error
 	defer c.mu.Unlock()
-	c.items.Put(key, &item[V]{value, time.Now().Add(c.timeout)})
+	c.items.Put(key, &item[V]{value: value, expiration: time.Now().Add(c.timeout)})
 }
 
+// Extend restarts the timeout of the key. Negative entries, and entries that already expired, are not extended.
 func (c *Expiration[K, V]) Extend(key K) {
 	c.mu.Lock()
-	v, ok := c.items.Get(key)
-	if ok {
-		v.expiration = time.Now().Add(c.timeout)
+	defer c.mu.Unlock()
+
+	it, ok := c.get(key)
+	if !ok || it.negative() {
+		return
 	}
-	c.mu.Unlock()
+	if it.expired() {
+		// not yet removed by the cleanup
+		c.items.Delete(key)
+		return
+	}
+	it.expiration = time.Now().Add(c.timeout)
 }
 
 func (c *Expiration[K, V]) Delete(key K) {
@@ -132,10 +239,20 @@ func (c *Expiration[K, V]) Delete(key K) {
 	c.items.Delete(key)
 }
 
-func cleanup[K comparable, V any](wp weak.Pointer[Expiration[K, V]], interval time.Duration, quit chan struct{}) {
+// cleanup removes the expired entries every interval and, if pressure is set,
+// sheds the coldest entries whenever a memory sample exceeds the limit
+func cleanup[K comparable, V any](wp weak.Pointer[Expiration[K, V]], interval time.Duration, pressure *memoryMonitor, quit <-chan struct{}) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
+	// a nil channel is never ready, disabling the sampling
+	var sample <-chan time.Time
+	if pressure != nil {
+		sampler := time.NewTicker(pressure.interval)
+		defer sampler.Stop()
+		sample = sampler.C
+	}
+
 	for {
 		select {
 		case <-ticker.C:
@@ -144,22 +261,47 @@ func cleanup[K comparable, V any](wp weak.Pointer[Expiration[K, V]], interval ti
 				return
 			}
 			c.deleteExpired()
+		case <-sample:
+			if !pressure.exceeded() {
+				continue
+			}
+			c := wp.Value()
+			if c == nil {
+				return
+			}
+			c.Shrink(pressure.fraction)
 		case <-quit:
 			return
 		}
 	}
 }
 
+// Shrink evicts the given fraction, between 0 and 1, of the least recently used entries.
+// It returns the number of evicted entries.
+func (c *Expiration[K, V]) Shrink(fraction float64) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.items.Shrink(fraction)
+}
+
 // Delete all expired items from the cache.
 func (c *Expiration[K, V]) deleteExpired() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	var expired []K
 	for k, v := range c.items.ReverseIterator() {
 		if !v.expired() {
+			if v.negative() {
+				// negative entries have their own TTL, so they are out of order
+				continue
+			}
 			// since the items are ordered by last access time, we can stop here
 			break
 		}
+		expired = append(expired, k)
+	}
 
+	for _, k := range expired {
 		c.items.Delete(k)
 	}
 }
@@ -1,7 +1,10 @@
 package cache_test
 
 import (
+	"fmt"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -28,25 +31,181 @@ func TestExpiration(t *testing.T) {
 	})
 
 	exp.Put("a", "A")
-	v, ok := exp.GetIfPresent("a")
-	require.True(t, ok)
+	v, presence := exp.GetIfPresent("a")
+	require.Equal(t, cache.Present, presence)
 	assert.Equal(t, "A", v)
 
 	exp.Put("b", "B")
 
-	v, ok = exp.GetIfPresent("b")
-	require.True(t, ok)
+	v, presence = exp.GetIfPresent("b")
+	require.Equal(t, cache.Present, presence)
 	assert.Equal(t, "B", v)
 
 	time.Sleep(500 * time.Millisecond)
 
-	_, ok = exp.GetIfPresent("a")
-	require.False(t, ok)
+	_, presence = exp.GetIfPresent("a")
+	require.Equal(t, cache.Absent, presence)
 
-	_, ok = exp.GetIfPresent("b")
-	require.False(t, ok)
+	_, presence = exp.GetIfPresent("b")
+	require.Equal(t, cache.Absent, presence)
 
 	require.Len(t, calls, 2)
 	assert.Equal(t, call{"a", "A"}, calls[0])
 	assert.Equal(t, call{"b", "B"}, calls[1])
 }
+
+func TestExpirationDisposeAndCollect(t *testing.T) {
+	exp := cache.NewExpiration[string, string](10, time.Second, time.Millisecond, nil)
+	exp.Put("a", "A")
+	exp.Dispose()
+	exp.Dispose()
+
+	// the GC cleanup must not stop the already stopped cleanup goroutine again
+	exp = nil
+	for range 3 {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNegativeCaching(t *testing.T) {
+	evicted := []string{}
+	exp := cache.NewExpiration(100, time.Minute, time.Minute, func(key string, value string) {
+		evicted = append(evicted, key)
+	}, cache.WithNegativeTTL[string, string](100*time.Millisecond))
+	t.Cleanup(exp.Dispose)
+
+	calls := 0
+	loader := func() (string, error) {
+		calls++
+		return "", fmt.Errorf("user 42: %w", cache.ErrNotFound)
+	}
+
+	_, presence := exp.GetIfPresent("42")
+	assert.Equal(t, cache.Absent, presence)
+
+	_, err := exp.Get("42", loader)
+	require.ErrorIs(t, err, cache.ErrNotFound)
+	_, err = exp.Get("42", loader)
+	require.ErrorIs(t, err, cache.ErrNotFound)
+	assert.EqualError(t, err, "user 42: not found", "the original error is returned")
+	assert.Equal(t, 1, calls, "the absence should be cached")
+
+	v, presence := exp.GetIfPresent("42")
+	assert.Equal(t, cache.NotFound, presence, "a cached absence is not a miss")
+	assert.Empty(t, v)
+
+	// the negative entry expires with its own TTL
+	time.Sleep(150 * time.Millisecond)
+	_, presence = exp.GetIfPresent("42")
+	assert.Equal(t, cache.Absent, presence)
+	_, err = exp.Get("42", loader)
+	require.ErrorIs(t, err, cache.ErrNotFound)
+	assert.Equal(t, 2, calls)
+
+	// a put replaces the negative entry
+	exp.Put("42", "Alice")
+	v, presence = exp.GetIfPresent("42")
+	assert.Equal(t, cache.Present, presence)
+	assert.Equal(t, "Alice", v)
+
+	// negative entries are not reported as evicted
+	exp.Get("43", loader)
+	exp.Delete("43")
+	exp.Delete("42")
+	assert.Equal(t, []string{"42"}, evicted)
+}
+
+func TestExtendExpired(t *testing.T) {
+	exp := cache.NewExpiration(100, 50*time.Millisecond, time.Hour, nil,
+		cache.WithNegativeTTL[string, string](50*time.Millisecond))
+	t.Cleanup(exp.Dispose)
+
+	calls := 0
+	loader := func() (string, error) {
+		calls++
+		return "", cache.ErrNotFound
+	}
+	_, err := exp.Get("42", loader)
+	require.ErrorIs(t, err, cache.ErrNotFound)
+	exp.Put("a", "A")
+
+	// the entries expired but were not removed yet, since the cleanup interval is long
+	time.Sleep(80 * time.Millisecond)
+	exp.Extend("42")
+	exp.Extend("a")
+
+	_, presence := exp.GetIfPresent("42")
+	assert.Equal(t, cache.Absent, presence, "an expired negative entry is not revived")
+	_, err = exp.Get("42", loader)
+	require.ErrorIs(t, err, cache.ErrNotFound)
+	assert.Equal(t, 2, calls)
+
+	_, presence = exp.GetIfPresent("a")
+	assert.Equal(t, cache.Absent, presence, "an expired entry is not revived")
+}
+
+func TestNegativeCachingDisabled(t *testing.T) {
+	exp := cache.NewExpiration[string, string](100, time.Minute, time.Minute, nil)
+	t.Cleanup(exp.Dispose)
+
+	calls := 0
+	loader := func() (string, error) {
+		calls++
+		return "", cache.ErrNotFound
+	}
+	for range 2 {
+		_, err := exp.Get("42", loader)
+		require.ErrorIs(t, err, cache.ErrNotFound)
+	}
+	assert.Equal(t, 2, calls)
+
+	_, presence := exp.GetIfPresent("42")
+	assert.Equal(t, cache.Absent, presence)
+}
+
+func TestExpirationMemoryPressure(t *testing.T) {
+	// the limit is exceeded once, when requested
+	var over atomic.Bool
+	var mu sync.Mutex
+	evicted := []string{}
+	exp := cache.NewExpiration(0, time.Hour, time.Hour, func(key string, value string) {
+		mu.Lock()
+		evicted = append(evicted, key)
+		mu.Unlock()
+	}, cache.WithExpirationMemoryPressure[string, string](cache.MemoryPressure{
+		SoftLimit: 1000,
+		Fraction:  0.5,
+		Interval:  5 * time.Millisecond,
+		Sample: func() uint64 {
+			if over.CompareAndSwap(true, false) {
+				return 2000
+			}
+			return 0
+		},
+	}))
+	t.Cleanup(exp.Dispose)
+
+	for i := range 4 {
+		exp.Put(fmt.Sprint(i), "v")
+	}
+	time.Sleep(20 * time.Millisecond)
+	_, presence := exp.GetIfPresent("0")
+	require.Equal(t, cache.Present, presence)
+
+	// the memory is sampled in the background, shedding entries of an idle cache without waiting for a Put
+	over.Store(true)
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(evicted) >= 2
+	}, time.Second, 5*time.Millisecond)
+
+	mu.Lock()
+	assert.Equal(t, []string{"1", "2"}, evicted)
+	mu.Unlock()
+	_, presence = exp.GetIfPresent("0")
+	assert.Equal(t, cache.Present, presence)
+	_, presence = exp.GetIfPresent("3")
+	assert.Equal(t, cache.Present, presence)
+}
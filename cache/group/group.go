@@ -0,0 +1,200 @@
+// Package group implements a distributed read-through cache, in the spirit of groupcache.
+//
+// Every process owns the keys that a consistent hash ring assigns to it. Owned keys are loaded,
+// at most once at a time, and kept in a local cache. Keys owned by other processes are fetched
+// from the owning peer and kept for a short period in a smaller hot cache.
+package group
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/quintans/ds/cache"
+)
+
+const (
+	defaultCapacity    = 1024
+	defaultTTL         = 5 * time.Minute
+	defaultHotCapacity = 128
+	defaultHotTTL      = 10 * time.Second
+)
+
+// Loader loads the value of a key from the source of truth, like a database
+type Loader func(ctx context.Context, key string) ([]byte, error)
+
+// Fetcher retrieves the value of a key from the peer that owns it
+type Fetcher interface {
+	Fetch(ctx context.Context, group string, key string) ([]byte, error)
+}
+
+// PeerPicker picks the peer that owns a key.
+// It returns false when the key is owned by the current process.
+type PeerPicker interface {
+	PickPeer(key string) (Fetcher, bool)
+}
+
+type Option func(*Group)
+
+// WithCapacity sets the maximum number of owned keys kept in the local cache
+func WithCapacity(capacity int) Option {
+	return func(g *Group) {
+		g.capacity = capacity
+	}
+}
+
+// WithTTL sets for how long an owned key is kept in the local cache after its last access
+func WithTTL(ttl time.Duration) Option {
+	return func(g *Group) {
+		g.ttl = ttl
+	}
+}
+
+// WithHotCache sets the size and the time to live of the cache holding keys owned by other peers.
+// The TTL should be short since these entries are not refreshed when the owner reloads them.
+func WithHotCache(capacity int, ttl time.Duration) Option {
+	return func(g *Group) {
+		g.hotCapacity = capacity
+		g.hotTTL = ttl
+	}
+}
+
+// WithPeers sets how the owners of the keys are found.
+// Without peers, the group behaves as a local read-through cache.
+func WithPeers(peers PeerPicker) Option {
+	return func(g *Group) {
+		g.peers = peers
+	}
+}
+
+// Stats are counters of the group activity
+type Stats struct {
+	Gets        int64 // calls to Get
+	HotHits     int64 // values served from the hot cache
+	PeerLoads   int64 // values fetched from peers
+	PeerErrors  int64 // failed fetches from peers
+	Loads       int64 // values loaded with the loader
+	LoadErrors  int64 // failed loads
+	ServerGets  int64 // requests served to peers
+	LocalMisses int64 // owned keys not found in the local cache
+}
+
+type stats struct {
+	gets        atomic.Int64
+	hotHits     atomic.Int64
+	peerLoads   atomic.Int64
+	peerErrors  atomic.Int64
+	loads       atomic.Int64
+	loadErrors  atomic.Int64
+	serverGets  atomic.Int64
+	localMisses atomic.Int64
+}
+
+// Group is a cache namespace, with its own loader, spread over a set of peers
+type Group struct {
+	name        string
+	loader      Loader
+	peers       PeerPicker
+	capacity    int
+	ttl         time.Duration
+	hotCapacity int
+	hotTTL      time.Duration
+	main        *cache.Expiration[string, []byte]
+	hot         *cache.Expiration[string, []byte]
+	stats       stats
+}
+
+// New creates a group. The name identifies the group among the peers.
+func New(name string, loader Loader, options ...Option) *Group {
+	g := &Group{
+		name:        name,
+		loader:      loader,
+		capacity:    defaultCapacity,
+		ttl:         defaultTTL,
+		hotCapacity: defaultHotCapacity,
+		hotTTL:      defaultHotTTL,
+	}
+
+	for _, opt := range options {
+		opt(g)
+	}
+
+	g.main = cache.NewExpiration[string, []byte](g.capacity, g.ttl, cleanupInterval(g.ttl), nil)
+	g.hot = cache.NewExpiration[string, []byte](g.hotCapacity, g.hotTTL, cleanupInterval(g.hotTTL), nil)
+	return g
+}
+
+func cleanupInterval(ttl time.Duration) time.Duration {
+	return max(ttl/2, time.Millisecond)
+}
+
+func (g *Group) Name() string {
+	return g.name
+}
+
+// Get returns the value of the key, either from the local caches, from the owning peer or from the loader.
+// The returned slice must not be modified.
+func (g *Group) Get(ctx context.Context, key string) ([]byte, error) {
+	g.stats.gets.Add(1)
+
+	if v, presence := g.hot.GetIfPresent(key); presence == cache.Present {
+		g.stats.hotHits.Add(1)
+		return v, nil
+	}
+
+	if g.peers != nil {
+		if peer, ok := g.peers.PickPeer(key); ok {
+			v, err := g.hot.Get(key, func() ([]byte, error) {
+				return peer.Fetch(ctx, g.name, key)
+			})
+			if err == nil {
+				g.stats.peerLoads.Add(1)
+				return v, nil
+			}
+			g.stats.peerErrors.Add(1)
+			// the owner is unavailable, so we fall back to loading it ourselves
+		}
+	}
+
+	return g.getLocally(ctx, key)
+}
+
+// getLocally returns the value of a key owned by this process, loading it if needed.
+// Concurrent calls for the same key only trigger one load.
+func (g *Group) getLocally(ctx context.Context, key string) ([]byte, error) {
+	return g.main.Get(key, func() ([]byte, error) {
+		g.stats.localMisses.Add(1)
+		v, err := g.loader(ctx, key)
+		if err != nil {
+			g.stats.loadErrors.Add(1)
+			return nil, err
+		}
+		g.stats.loads.Add(1)
+		return v, nil
+	})
+}
+
+// Remove removes the key from the local caches of this process
+func (g *Group) Remove(key string) {
+	g.main.Delete(key)
+	g.hot.Delete(key)
+}
+
+func (g *Group) Stats() Stats {
+	return Stats{
+		Gets:        g.stats.gets.Load(),
+		HotHits:     g.stats.hotHits.Load(),
+		PeerLoads:   g.stats.peerLoads.Load(),
+		PeerErrors:  g.stats.peerErrors.Load(),
+		Loads:       g.stats.loads.Load(),
+		LoadErrors:  g.stats.loadErrors.Load(),
+		ServerGets:  g.stats.serverGets.Load(),
+		LocalMisses: g.stats.localMisses.Load(),
+	}
+}
+
+// Close releases the resources of the group
+func (g *Group) Close() {
+	g.main.Dispose()
+	g.hot.Dispose()
+}
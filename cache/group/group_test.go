@@ -0,0 +1,163 @@
+package group_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quintans/ds/cache/group"
+)
+
+type peer struct {
+	url    string
+	server *httptest.Server
+	pool   *group.HTTPPool
+	group  *group.Group
+	loads  *atomic.Int64
+}
+
+// startPeers starts n peers on loopback sharing the same loader and knowing each other
+func startPeers(t *testing.T, n int, loader group.Loader) []*peer {
+	peers := make([]*peer, n)
+	urls := make([]string, n)
+	for i := range n {
+		mux := http.NewServeMux()
+		srv := httptest.NewServer(mux)
+		t.Cleanup(srv.Close)
+
+		loads := &atomic.Int64{}
+		pool := group.NewHTTPPool(srv.URL)
+		g := pool.NewGroup("users", func(ctx context.Context, key string) ([]byte, error) {
+			loads.Add(1)
+			return loader(ctx, key)
+		}, group.WithHotCache(64, time.Minute))
+		t.Cleanup(g.Close)
+		mux.Handle("/_group/", pool)
+
+		peers[i] = &peer{url: srv.URL, server: srv, pool: pool, group: g, loads: loads}
+		urls[i] = srv.URL
+	}
+	for _, p := range peers {
+		p.pool.Set(urls...)
+	}
+	return peers
+}
+
+func dbLoader(ctx context.Context, key string) ([]byte, error) {
+	if key == "missing" {
+		return nil, errors.New("not found")
+	}
+	return []byte("value of " + key), nil
+}
+
+func TestGroupLoadsOncePerKey(t *testing.T) {
+	peers := startPeers(t, 3, dbLoader)
+	ctx := context.Background()
+
+	keys := make([]string, 30)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("user/%d", i)
+	}
+
+	// every peer asks for every key, concurrently
+	var wg sync.WaitGroup
+	for _, p := range peers {
+		wg.Go(func() {
+			for _, key := range keys {
+				v, err := p.group.Get(ctx, key)
+				assert.NoError(t, err)
+				assert.Equal(t, "value of "+key, string(v))
+			}
+		})
+	}
+	wg.Wait()
+
+	var loads int64
+	owners := map[string]bool{}
+	for _, p := range peers {
+		loads += p.loads.Load()
+		if p.loads.Load() > 0 {
+			owners[p.url] = true
+		}
+		assert.Zero(t, p.group.Stats().PeerErrors)
+	}
+	assert.Equal(t, int64(len(keys)), loads, "each key should be loaded by its owner only")
+	assert.Len(t, owners, 3, "keys should be spread among all peers")
+
+	// remote keys are now served from the hot cache
+	before := peers[0].group.Stats()
+	for _, key := range keys {
+		_, err := peers[0].group.Get(ctx, key)
+		require.NoError(t, err)
+	}
+	after := peers[0].group.Stats()
+	remote := 0
+	for _, key := range keys {
+		if _, ok := peers[0].pool.PickPeer(key); ok {
+			remote++
+		}
+	}
+	assert.Equal(t, before.PeerLoads, after.PeerLoads)
+	assert.Equal(t, int64(remote), after.HotHits-before.HotHits)
+}
+
+func TestGroupLoaderError(t *testing.T) {
+	peers := startPeers(t, 2, dbLoader)
+
+	for _, p := range peers {
+		_, err := p.group.Get(context.Background(), "missing")
+		require.Error(t, err)
+	}
+}
+
+func TestGroupFallbackWhenPeerIsDown(t *testing.T) {
+	peers := startPeers(t, 2, dbLoader)
+	ctx := context.Background()
+
+	// find a key owned by the second peer
+	var key string
+	for i := 0; ; i++ {
+		key = fmt.Sprintf("k%d", i)
+		if _, remote := peers[0].pool.PickPeer(key); remote {
+			break
+		}
+	}
+
+	peers[1].server.Close()
+
+	v, err := peers[0].group.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, "value of "+key, string(v))
+	assert.Equal(t, int64(1), peers[0].group.Stats().PeerErrors)
+	assert.Equal(t, int64(1), peers[0].loads.Load())
+}
+
+func TestLocalGroup(t *testing.T) {
+	var loads atomic.Int64
+	g := group.New("local", func(ctx context.Context, key string) ([]byte, error) {
+		loads.Add(1)
+		return []byte(key), nil
+	})
+	t.Cleanup(g.Close)
+
+	for range 3 {
+		v, err := g.Get(context.Background(), "a")
+		require.NoError(t, err)
+		assert.Equal(t, "a", string(v))
+	}
+	assert.Equal(t, int64(1), loads.Load())
+
+	g.Remove("a")
+	_, err := g.Get(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), loads.Load())
+}
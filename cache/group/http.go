@@ -0,0 +1,184 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBasePath = "/_group/"
+	defaultReplicas = 50
+	defaultTimeout  = 5 * time.Second
+)
+
+type HTTPPoolOption func(*HTTPPool)
+
+// WithBasePath sets the path prefix under which the pool serves peer requests
+func WithBasePath(basePath string) HTTPPoolOption {
+	return func(p *HTTPPool) {
+		p.basePath = basePath
+	}
+}
+
+// WithReplicas sets the number of virtual nodes of each peer in the hash ring
+func WithReplicas(replicas int) HTTPPoolOption {
+	return func(p *HTTPPool) {
+		p.replicas = replicas
+	}
+}
+
+// WithHTTPClient sets the client used to fetch keys from other peers
+func WithHTTPClient(client *http.Client) HTTPPoolOption {
+	return func(p *HTTPPool) {
+		p.client = client
+	}
+}
+
+// HTTPPool is a set of peers communicating over HTTP.
+// It picks the owner of each key and serves the keys owned by this process to the other peers.
+type HTTPPool struct {
+	self     string
+	basePath string
+	replicas int
+	client   *http.Client
+
+	mu      sync.RWMutex
+	ring    *Ring
+	fetcher map[string]*httpFetcher
+	groups  map[string]*Group
+}
+
+// NewHTTPPool creates a pool for the peer reachable at self, a base URL like "http://10.0.0.1:8080".
+func NewHTTPPool(self string, options ...HTTPPoolOption) *HTTPPool {
+	p := &HTTPPool{
+		self:     self,
+		basePath: defaultBasePath,
+		replicas: defaultReplicas,
+		client:   &http.Client{Timeout: defaultTimeout},
+		groups:   map[string]*Group{},
+	}
+
+	for _, opt := range options {
+		opt(p)
+	}
+
+	p.Set(self)
+	return p
+}
+
+// Set replaces the peers of the pool. Each peer is a base URL, and should include this peer.
+func (p *HTTPPool) Set(peers ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.ring = NewRing(p.replicas, nil)
+	p.ring.Add(peers...)
+	p.fetcher = make(map[string]*httpFetcher, len(peers))
+	for _, peer := range peers {
+		p.fetcher[peer] = &httpFetcher{
+			baseURL: strings.TrimSuffix(peer, "/") + p.basePath,
+			client:  p.client,
+		}
+	}
+}
+
+// PickPeer implements PeerPicker
+func (p *HTTPPool) PickPeer(key string) (Fetcher, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	peer := p.ring.Get(key)
+	if peer == "" || peer == p.self {
+		return nil, false
+	}
+	return p.fetcher[peer], true
+}
+
+// NewGroup creates a group whose keys are distributed among the peers of this pool,
+// and registers it so that it can serve the keys it owns to the other peers.
+func (p *HTTPPool) NewGroup(name string, loader Loader, options ...Option) *Group {
+	g := New(name, loader, append(options, WithPeers(p))...)
+
+	p.mu.Lock()
+	p.groups[name] = g
+	p.mu.Unlock()
+
+	return g
+}
+
+// ServeHTTP serves the requests of the other peers, at <basePath><group>/<key>
+func (p *HTTPPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.EscapedPath()
+	if !strings.HasPrefix(path, p.basePath) {
+		http.NotFound(w, r)
+		return
+	}
+	groupName, key, ok := strings.Cut(path[len(p.basePath):], "/")
+	if !ok {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	groupName, err := url.PathUnescape(groupName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	key, err = url.PathUnescape(key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p.mu.RLock()
+	g, ok := p.groups[groupName]
+	p.mu.RUnlock()
+	if !ok {
+		http.Error(w, "no such group: "+groupName, http.StatusNotFound)
+		return
+	}
+
+	g.stats.serverGets.Add(1)
+	// the requester considers us the owner, so we never forward the request,
+	// even if our view of the ring is different, to avoid loops
+	v, err := g.getLocally(r.Context(), key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(v)
+}
+
+type httpFetcher struct {
+	baseURL string
+	client  *http.Client
+}
+
+func (h *httpFetcher) Fetch(ctx context.Context, group string, key string) ([]byte, error) {
+	u := h.baseURL + url.PathEscape(group) + "/" + url.PathEscape(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response body from %s: %w", u, err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("peer %s returned %s: %s", u, res.Status, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
@@ -0,0 +1,90 @@
+package group
+
+import (
+	"hash/crc32"
+	"slices"
+	"strconv"
+)
+
+// Hash maps bytes to a position in the ring
+type Hash func(data []byte) uint32
+
+// Ring is a consistent hash ring.
+// Each node is placed in the ring several times, as virtual nodes, to spread the keys evenly.
+// It is not safe for concurrent use.
+type Ring struct {
+	hash     Hash
+	replicas int
+	points   []uint32 // sorted
+	// nodes holds the nodes placed at each point, sorted.
+	// On a collision the lowest name owns the point, whatever the order the nodes were added.
+	nodes map[uint32][]string
+}
+
+// NewRing creates a ring where each node has the given number of virtual nodes.
+// If hash is nil, crc32.ChecksumIEEE is used.
+func NewRing(replicas int, hash Hash) *Ring {
+	if hash == nil {
+		hash = crc32.ChecksumIEEE
+	}
+	return &Ring{
+		hash:     hash,
+		replicas: max(replicas, 1),
+		nodes:    map[uint32][]string{},
+	}
+}
+
+// Add places the nodes in the ring
+func (r *Ring) Add(nodes ...string) {
+	for _, node := range nodes {
+		for i := range r.replicas {
+			p := r.hash([]byte(strconv.Itoa(i) + node))
+			owners, ok := r.nodes[p]
+			if !ok {
+				r.points = append(r.points, p)
+			}
+			if j, found := slices.BinarySearch(owners, node); !found {
+				r.nodes[p] = slices.Insert(owners, j, node)
+			}
+		}
+	}
+	slices.Sort(r.points)
+}
+
+// Remove takes the node out of the ring
+func (r *Ring) Remove(node string) {
+	r.points = slices.DeleteFunc(r.points, func(p uint32) bool {
+		owners := r.nodes[p]
+		j, found := slices.BinarySearch(owners, node)
+		if !found {
+			return false
+		}
+		owners = slices.Delete(owners, j, j+1)
+		if len(owners) == 0 {
+			delete(r.nodes, p)
+			return true
+		}
+		r.nodes[p] = owners
+		return false
+	})
+}
+
+// IsEmpty returns true if there are no nodes in the ring
+func (r *Ring) IsEmpty() bool {
+	return len(r.points) == 0
+}
+
+// Get returns the node owning the key, that is the first node clockwise from the key position.
+// Returns an empty string if the ring is empty.
+func (r *Ring) Get(key string) string {
+	if r.IsEmpty() {
+		return ""
+	}
+	p := r.hash([]byte(key))
+	i, _ := slices.BinarySearch(r.points, p)
+	if i == len(r.points) {
+		// wrap around
+		i = 0
+	}
+	return r.nodes[r.points[i]][0]
+}
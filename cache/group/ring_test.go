@@ -0,0 +1,90 @@
+package group_test
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quintans/ds/cache/group"
+)
+
+func TestRing(t *testing.T) {
+	// hash that maps numbers to themselves, to have predictable positions
+	ring := group.NewRing(3, func(data []byte) uint32 {
+		n, err := strconv.Atoi(string(data))
+		require.NoError(t, err)
+		return uint32(n)
+	})
+	assert.True(t, ring.IsEmpty())
+	assert.Equal(t, "", ring.Get("1"))
+
+	// virtual nodes: 2, 4, 6 -> 02, 12, 22, 04, 14, 24, 06, 16, 26
+	ring.Add("6", "4", "2")
+
+	tests := map[string]string{
+		"2":  "2",
+		"11": "2",
+		"23": "4",
+		"27": "2", // wraps around
+	}
+	for key, node := range tests {
+		assert.Equal(t, node, ring.Get(key), "key %s", key)
+	}
+
+	ring.Add("8")
+	assert.Equal(t, "8", ring.Get("27"))
+
+	ring.Remove("8")
+	assert.Equal(t, "2", ring.Get("27"))
+}
+
+func TestRingStability(t *testing.T) {
+	ring := group.NewRing(50, nil)
+	ring.Add("a", "b", "c")
+
+	owners := map[string]string{}
+	counts := map[string]int{}
+	for i := range 3000 {
+		key := fmt.Sprintf("key-%d", i)
+		owners[key] = ring.Get(key)
+		counts[owners[key]]++
+	}
+	for _, node := range []string{"a", "b", "c"} {
+		assert.InDelta(t, 1000, counts[node], 400, "node %s should own about a third of the keys", node)
+	}
+
+	// adding a node only moves keys to the new node
+	ring.Add("d")
+	moved := 0
+	for key, owner := range owners {
+		now := ring.Get(key)
+		if now != owner {
+			assert.Equal(t, "d", now)
+			moved++
+		}
+	}
+	assert.InDelta(t, 750, moved, 350)
+}
+
+func TestRingCollision(t *testing.T) {
+	// every virtual node collides at the same point
+	hash := func(data []byte) uint32 {
+		return 1
+	}
+
+	ab := group.NewRing(2, hash)
+	ab.Add("a", "b")
+	ba := group.NewRing(2, hash)
+	ba.Add("b", "a")
+	assert.Equal(t, "a", ab.Get("key"), "the lowest name owns the point")
+	assert.Equal(t, "a", ba.Get("key"), "ownership must not depend on the insertion order")
+
+	// the point is handed over to the other node
+	ab.Remove("a")
+	assert.Equal(t, "b", ab.Get("key"))
+	ab.Remove("b")
+	assert.True(t, ab.IsEmpty())
+}
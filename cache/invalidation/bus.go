@@ -0,0 +1,153 @@
+// Package invalidation propagates cache invalidations across instances.
+//
+// An Invalidator wraps a local cache. Deleting a key, or invalidating a tag, through it removes the
+// entries locally and publishes an event on a Bus. The other instances subscribed to the same bus
+// apply the event to their own local cache.
+package invalidation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+// Reason tells why an entry was invalidated
+type Reason int
+
+const (
+	// Deleted means the entry was explicitly deleted
+	Deleted Reason = iota + 1
+	// Updated means the source value changed and the cached copy is stale
+	Updated
+	// Expired means the entry is no longer valid
+	Expired
+	// TagInvalidated means the entry was removed because one of its tags was invalidated
+	TagInvalidated
+)
+
+var reasonNames = map[Reason]string{
+	Deleted:        "deleted",
+	Updated:        "updated",
+	Expired:        "expired",
+	TagInvalidated: "tag_invalidated",
+}
+
+func (r Reason) String() string {
+	if s, ok := reasonNames[r]; ok {
+		return s
+	}
+	return fmt.Sprintf("Reason(%d)", int(r))
+}
+
+func (r Reason) MarshalText() ([]byte, error) {
+	s, ok := reasonNames[r]
+	if !ok {
+		return nil, fmt.Errorf("unknown invalidation reason: %d", int(r))
+	}
+	return []byte(s), nil
+}
+
+func (r *Reason) UnmarshalText(text []byte) error {
+	for k, v := range reasonNames {
+		if v == string(text) {
+			*r = k
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown invalidation reason: %q", text)
+}
+
+// Event is an invalidation broadcast to all the instances.
+// Either Key or Tag is set.
+type Event struct {
+	// ID uniquely identifies the event, so that it is applied only once
+	ID string `json:"id"`
+	// Origin identifies the instance that published the event
+	Origin string `json:"origin"`
+	Key    string `json:"key,omitempty"`
+	Tag    string `json:"tag,omitempty"`
+	Reason Reason `json:"reason"`
+}
+
+// Bus delivers events to all subscribers, including the ones of the publishing instance.
+type Bus interface {
+	Publish(ctx context.Context, event Event) error
+	// Subscribe registers a handler for the published events, returning a function to unsubscribe it
+	Subscribe(handler func(Event)) (unsubscribe func())
+	Close() error
+}
+
+// subscribers is a set of event handlers safe for concurrent use
+type subscribers struct {
+	mu       sync.RWMutex
+	next     int
+	handlers map[int]func(Event)
+}
+
+func (s *subscribers) add(handler func(Event)) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.handlers == nil {
+		s.handlers = map[int]func(Event){}
+	}
+	id := s.next
+	s.next++
+	s.handlers[id] = handler
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.handlers, id)
+	}
+}
+
+func (s *subscribers) deliver(event Event) {
+	s.mu.RLock()
+	handlers := make([]func(Event), 0, len(s.handlers))
+	for _, h := range s.handlers {
+		handlers = append(handlers, h)
+	}
+	s.mu.RUnlock()
+
+	for _, h := range handlers {
+		h(event)
+	}
+}
+
+func (s *subscribers) clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers = nil
+}
+
+// MemoryBus delivers events synchronously to the subscribers in the same process
+type MemoryBus struct {
+	subs subscribers
+}
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{}
+}
+
+func (b *MemoryBus) Publish(_ context.Context, event Event) error {
+	b.subs.deliver(event)
+	return nil
+}
+
+func (b *MemoryBus) Subscribe(handler func(Event)) func() {
+	return b.subs.add(handler)
+}
+
+func (b *MemoryBus) Close() error {
+	b.subs.clear()
+	return nil
+}
+
+func newID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,135 @@
+package invalidation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const defaultTimeout = 5 * time.Second
+
+type HTTPBusOption func(*HTTPBus)
+
+// WithPeers sets the webhook URLs where the events are posted
+func WithPeers(urls ...string) HTTPBusOption {
+	return func(b *HTTPBus) {
+		b.peers = urls
+	}
+}
+
+// WithHTTPClient sets the client used to post the events
+func WithHTTPClient(client *http.Client) HTTPBusOption {
+	return func(b *HTTPBus) {
+		b.client = client
+	}
+}
+
+// HTTPBus broadcasts events to other instances by posting them, as JSON, to their webhooks.
+// It is also the http.Handler of the webhook of this instance, delivering the received events
+// to the local subscribers.
+// Received events are never forwarded, so there are no loops between instances.
+type HTTPBus struct {
+	mu     sync.RWMutex
+	peers  []string
+	client *http.Client
+	subs   subscribers
+}
+
+func NewHTTPBus(options ...HTTPBusOption) *HTTPBus {
+	b := &HTTPBus{
+		client: &http.Client{Timeout: defaultTimeout},
+	}
+
+	for _, opt := range options {
+		opt(b)
+	}
+
+	return b
+}
+
+// SetPeers replaces the webhook URLs where the events are posted
+func (b *HTTPBus) SetPeers(urls ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.peers = slices.Clone(urls)
+}
+
+// Publish delivers the event to the local subscribers and posts it to all peers.
+// Failing peers do not prevent the delivery to the others, and their errors are joined.
+func (b *HTTPBus) Publish(ctx context.Context, event Event) error {
+	b.subs.deliver(event)
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	b.mu.RLock()
+	peers := b.peers
+	b.mu.RUnlock()
+
+	errs := make([]error, len(peers))
+	var wg sync.WaitGroup
+	for i, peer := range peers {
+		wg.Go(func() {
+			errs[i] = b.post(ctx, peer, body)
+		})
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func (b *HTTPBus) post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("posting invalidation to %s: %s", url, res.Status)
+	}
+	return nil
+}
+
+func (b *HTTPBus) Subscribe(handler func(Event)) func() {
+	return b.subs.add(handler)
+}
+
+// ServeHTTP receives the events posted by the other instances
+func (b *HTTPBus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var event Event
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	b.subs.deliver(event)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (b *HTTPBus) Close() error {
+	b.subs.clear()
+	return nil
+}
@@ -0,0 +1,223 @@
+package invalidation
+
+import (
+	"context"
+	"sync"
+
+	"github.com/quintans/ds/cache"
+	"github.com/quintans/ds/collections/set"
+)
+
+const defaultSeenCapacity = 4096
+
+// Deleter is a cache whose entries can be deleted, like cache.LRU or cache.Expiration
+type Deleter[K comparable] interface {
+	Delete(key K)
+}
+
+type Option func(*Invalidator)
+
+// WithID sets the identifier of this instance. By default a random one is generated.
+func WithID(id string) Option {
+	return func(i *Invalidator) {
+		i.id = id
+	}
+}
+
+// WithListener sets a function called for every key invalidated in the local cache,
+// be it by a local call or by an event from another instance.
+func WithListener(listener func(key string, event Event)) Option {
+	return func(i *Invalidator) {
+		i.listener = listener
+	}
+}
+
+// WithLocker sets the lock held while deleting from the target cache.
+// It is needed for caches that are not safe for concurrent use, like cache.LRU, since
+// buses like HTTPBus deliver events in their own goroutines.
+func WithLocker(locker sync.Locker) Option {
+	return func(i *Invalidator) {
+		i.locker = locker
+	}
+}
+
+// WithSeenCapacity sets how many event IDs are remembered to discard duplicated deliveries
+func WithSeenCapacity(capacity int) Option {
+	return func(i *Invalidator) {
+		i.seenCapacity = capacity
+	}
+}
+
+// Invalidator removes entries from a local cache and broadcasts the removal to the other instances.
+type Invalidator struct {
+	id           string
+	bus          Bus
+	target       Deleter[string]
+	listener     func(key string, event Event)
+	locker       sync.Locker
+	seenCapacity int
+
+	mu      sync.Mutex
+	seen    *cache.LRU[string, struct{}]
+	tags    map[string]*set.Set[string, string] // keys by tag
+	keyTags map[string]*set.Set[string, string] // tags by key
+
+	unsubscribe func()
+}
+
+// New creates an invalidator for the target cache, subscribing it to the events of the bus.
+// When keys are tagged, the eviction callback of the target cache must call Untag.
+func New(bus Bus, target Deleter[string], options ...Option) *Invalidator {
+	i := &Invalidator{
+		bus:          bus,
+		target:       target,
+		seenCapacity: defaultSeenCapacity,
+		tags:         map[string]*set.Set[string, string]{},
+		keyTags:      map[string]*set.Set[string, string]{},
+	}
+
+	for _, opt := range options {
+		opt(i)
+	}
+
+	if i.id == "" {
+		i.id = newID()
+	}
+	i.seen = cache.NewLRU[string, struct{}](i.seenCapacity, nil)
+	i.unsubscribe = bus.Subscribe(i.receive)
+	return i
+}
+
+// ID returns the identifier of this instance in the published events
+func (i *Invalidator) ID() string {
+	return i.id
+}
+
+// Tag associates tags to a key, so that it can be invalidated in group with InvalidateTag.
+func (i *Invalidator) Tag(key string, tags ...string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	kt, ok := i.keyTags[key]
+	if !ok {
+		kt = set.New[string]()
+		i.keyTags[key] = kt
+	}
+	for _, tag := range tags {
+		kt.Add(tag)
+		keys, ok := i.tags[tag]
+		if !ok {
+			keys = set.New[string]()
+			i.tags[tag] = keys
+		}
+		keys.Add(key)
+	}
+}
+
+// Untag removes the key from the tag indexes, without invalidating it.
+// It must be called from the eviction callback of the target cache, so that the tags of entries
+// evicted by the cache itself, like by LRU or expiration, do not stay in the indexes forever.
+func (i *Invalidator) Untag(key string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.untag(key)
+}
+
+// Delete removes the key from the local cache and from the caches of the other instances.
+func (i *Invalidator) Delete(ctx context.Context, key string) error {
+	return i.Invalidate(ctx, key, Deleted)
+}
+
+// Invalidate removes the key from the local cache and from the caches of the other instances,
+// reporting the reason to their listeners.
+func (i *Invalidator) Invalidate(ctx context.Context, key string, reason Reason) error {
+	return i.publish(ctx, Event{Key: key, Reason: reason})
+}
+
+// InvalidateTag removes all the keys with the tag from the local cache and from the caches of the other instances.
+// Each instance removes the keys it has tagged itself.
+func (i *Invalidator) InvalidateTag(ctx context.Context, tag string) error {
+	return i.publish(ctx, Event{Tag: tag, Reason: TagInvalidated})
+}
+
+func (i *Invalidator) publish(ctx context.Context, event Event) error {
+	event.ID = newID()
+	event.Origin = i.id
+
+	// apply locally first, so that the local cache is consistent even if the broadcast fails
+	i.apply(event)
+	return i.bus.Publish(ctx, event)
+}
+
+// receive handles the events coming from the bus
+func (i *Invalidator) receive(event Event) {
+	// loop suppression: our own events were already applied when published
+	if event.Origin == i.id {
+		return
+	}
+	i.apply(event)
+}
+
+// apply removes the entries targeted by the event, unless it was already applied
+func (i *Invalidator) apply(event Event) {
+	i.mu.Lock()
+	if _, ok := i.seen.Get(event.ID); ok {
+		i.mu.Unlock()
+		return
+	}
+	i.seen.Put(event.ID, struct{}{})
+
+	var keys []string
+	if event.Tag != "" {
+		if tagged, ok := i.tags[event.Tag]; ok {
+			for k := range tagged.Values() {
+				keys = append(keys, k)
+			}
+		}
+	} else {
+		keys = []string{event.Key}
+	}
+	for _, k := range keys {
+		i.untag(k)
+	}
+	i.mu.Unlock()
+
+	if i.locker != nil {
+		i.locker.Lock()
+	}
+	for _, k := range keys {
+		i.target.Delete(k)
+	}
+	if i.locker != nil {
+		i.locker.Unlock()
+	}
+
+	for _, k := range keys {
+		if i.listener != nil {
+			i.listener(k, event)
+		}
+	}
+}
+
+// untag removes the key from the tag indexes
+func (i *Invalidator) untag(key string) {
+	kt, ok := i.keyTags[key]
+	if !ok {
+		return
+	}
+	for tag := range kt.Values() {
+		if keys, ok := i.tags[tag]; ok {
+			keys.Delete(key)
+			if keys.Size() == 0 {
+				delete(i.tags, tag)
+			}
+		}
+	}
+	delete(i.keyTags, key)
+}
+
+// Close stops receiving events from the bus
+func (i *Invalidator) Close() {
+	i.unsubscribe()
+}
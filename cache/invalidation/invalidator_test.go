@@ -0,0 +1,207 @@
+package invalidation_test
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quintans/ds/cache"
+	"github.com/quintans/ds/cache/invalidation"
+)
+
+type record struct {
+	key    string
+	reason invalidation.Reason
+	origin string
+}
+
+// instance is a local cache with its invalidator
+type instance struct {
+	mu            sync.Mutex
+	lru           *cache.LRU[string, string]
+	inv           *invalidation.Invalidator
+	evicted       []string
+	invalidations []record
+}
+
+func newInstance(t *testing.T, id string, bus invalidation.Bus) *instance {
+	in := &instance{}
+	in.lru = cache.NewLRU(10, func(key string, value string) {
+		in.evicted = append(in.evicted, key)
+		in.inv.Untag(key)
+	})
+	in.inv = invalidation.New(bus, in.lru,
+		invalidation.WithID(id),
+		invalidation.WithLocker(&in.mu),
+		invalidation.WithListener(func(key string, event invalidation.Event) {
+			in.mu.Lock()
+			defer in.mu.Unlock()
+			in.invalidations = append(in.invalidations, record{key, event.Reason, event.Origin})
+		}),
+	)
+	t.Cleanup(in.inv.Close)
+	return in
+}
+
+func (in *instance) put(key, value string, tags ...string) {
+	in.mu.Lock()
+	in.lru.Put(key, value)
+	in.mu.Unlock()
+	in.inv.Tag(key, tags...)
+}
+
+func (in *instance) has(key string) bool {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	_, ok := in.lru.Get(key)
+	return ok
+}
+
+func (in *instance) received() []record {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return append([]record(nil), in.invalidations...)
+}
+
+func TestMemoryBus(t *testing.T) {
+	bus := invalidation.NewMemoryBus()
+	a := newInstance(t, "a", bus)
+	b := newInstance(t, "b", bus)
+	ctx := context.Background()
+
+	for _, in := range []*instance{a, b} {
+		in.put("user:1", "Alice", "users")
+		in.put("user:2", "Bob", "users")
+		in.put("order:1", "book")
+	}
+
+	require.NoError(t, a.inv.Invalidate(ctx, "order:1", invalidation.Updated))
+	assert.False(t, a.has("order:1"))
+	assert.False(t, b.has("order:1"))
+	assert.Equal(t, []record{{"order:1", invalidation.Updated, "a"}}, a.received())
+	assert.Equal(t, []record{{"order:1", invalidation.Updated, "a"}}, b.received())
+
+	require.NoError(t, b.inv.InvalidateTag(ctx, "users"))
+	for _, in := range []*instance{a, b} {
+		assert.False(t, in.has("user:1"))
+		assert.False(t, in.has("user:2"))
+		assert.ElementsMatch(t, []string{"order:1", "user:1", "user:2"}, in.evicted)
+		assert.ElementsMatch(t, []record{
+			{"order:1", invalidation.Updated, "a"},
+			{"user:1", invalidation.TagInvalidated, "b"},
+			{"user:2", invalidation.TagInvalidated, "b"},
+		}, in.received())
+	}
+}
+
+func TestLoopSuppression(t *testing.T) {
+	bus := invalidation.NewMemoryBus()
+	a := newInstance(t, "a", bus)
+	b := newInstance(t, "b", bus)
+	ctx := context.Background()
+
+	// the same event delivered more than once, as happens when it is relayed, is applied once
+	b.put("k", "v")
+	event := invalidation.Event{ID: "1", Origin: "x", Key: "k", Reason: invalidation.Deleted}
+	require.NoError(t, bus.Publish(ctx, event))
+	b.put("k", "v")
+	require.NoError(t, bus.Publish(ctx, event))
+	assert.True(t, b.has("k"))
+	assert.Len(t, b.received(), 1)
+
+	// events coming back to their origin are ignored
+	a.put("k", "v")
+	require.NoError(t, bus.Publish(ctx, invalidation.Event{ID: "2", Origin: "a", Key: "k", Reason: invalidation.Deleted}))
+	assert.True(t, a.has("k"))
+	assert.Len(t, a.received(), 1, "only the event from x")
+}
+
+func TestEvictionUntags(t *testing.T) {
+	a := newInstance(t, "a", invalidation.NewMemoryBus())
+	ctx := context.Background()
+
+	a.put("k0", "v", "tag")
+	// fill the cache so that k0 is evicted
+	for i := range 10 {
+		a.put(fmt.Sprintf("k%d", i+1), "v")
+	}
+	assert.False(t, a.has("k0"))
+
+	// k0 is cached again without tags, so the tag it had before being evicted no longer applies
+	a.put("k0", "v")
+	require.NoError(t, a.inv.InvalidateTag(ctx, "tag"))
+	assert.True(t, a.has("k0"))
+	assert.Empty(t, a.received())
+}
+
+func TestHTTPBus(t *testing.T) {
+	busA := invalidation.NewHTTPBus()
+	busB := invalidation.NewHTTPBus()
+	busC := invalidation.NewHTTPBus()
+	srvA := httptest.NewServer(busA)
+	t.Cleanup(srvA.Close)
+	srvB := httptest.NewServer(busB)
+	t.Cleanup(srvB.Close)
+	srvC := httptest.NewServer(busC)
+	t.Cleanup(srvC.Close)
+
+	// every instance knows all the webhooks, including its own
+	for _, bus := range []*invalidation.HTTPBus{busA, busB, busC} {
+		bus.SetPeers(srvA.URL, srvB.URL, srvC.URL)
+	}
+
+	a := newInstance(t, "a", busA)
+	b := newInstance(t, "b", busB)
+	c := newInstance(t, "c", busC)
+	for _, in := range []*instance{a, b, c} {
+		in.put("k1", "v1", "tag")
+		in.put("k2", "v2")
+	}
+
+	require.NoError(t, a.inv.Delete(context.Background(), "k2"))
+	for _, in := range []*instance{a, b, c} {
+		assert.False(t, in.has("k2"))
+		assert.True(t, in.has("k1"))
+		assert.Equal(t, []record{{"k2", invalidation.Deleted, "a"}}, in.received(), "each instance applies the event once")
+	}
+
+	require.NoError(t, c.inv.InvalidateTag(context.Background(), "tag"))
+	for _, in := range []*instance{a, b, c} {
+		assert.False(t, in.has("k1"))
+		assert.Len(t, in.received(), 2)
+	}
+}
+
+func TestHTTPBusPeerDown(t *testing.T) {
+	bus := invalidation.NewHTTPBus()
+	srv := httptest.NewServer(invalidation.NewHTTPBus())
+	srv.Close()
+	bus.SetPeers(srv.URL)
+
+	a := newInstance(t, "a", bus)
+	a.put("k", "v")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := a.inv.Delete(ctx, "k")
+	require.Error(t, err)
+	assert.False(t, a.has("k"), "the local cache is invalidated even when peers are unreachable")
+}
+
+func TestReasonText(t *testing.T) {
+	for _, r := range []invalidation.Reason{invalidation.Deleted, invalidation.Updated, invalidation.Expired, invalidation.TagInvalidated} {
+		text, err := r.MarshalText()
+		require.NoError(t, err)
+		var decoded invalidation.Reason
+		require.NoError(t, decoded.UnmarshalText(text))
+		assert.Equal(t, r, decoded)
+	}
+	var r invalidation.Reason
+	assert.Error(t, r.UnmarshalText([]byte("unknown")))
+}
@@ -1,6 +1,9 @@
 package cache
 
-import "iter"
+import (
+	"iter"
+	"math"
+)
 
 // NodeKV represents a node in the doubly linked list
 type NodeKV[K comparable, V any] struct {
@@ -19,14 +22,34 @@ type LRU[K comparable, V any] struct {
 	head     *NodeKV[K, V]
 	tail     *NodeKV[K, V]
 	onEvict  func(key K, value V)
+	pressure *memoryMonitor
 }
 
-func NewLRU[K comparable, V any](capacity int, onEvict func(key K, value V)) *LRU[K, V] {
-	return &LRU[K, V]{
+type LRUOption[K comparable, V any] func(*LRU[K, V])
+
+// WithLRUMemoryPressure sheds the coldest entries when the memory in use exceeds the limit.
+// The memory is sampled on Put, at most once every interval, since the LRU is not safe for concurrent use
+// and so it cannot be shrunk in the background.
+func WithLRUMemoryPressure[K comparable, V any](mp MemoryPressure) LRUOption[K, V] {
+	return func(l *LRU[K, V]) {
+		l.pressure = newMemoryMonitor(mp)
+	}
+}
+
+// NewLRU creates a LRU cache that holds at most capacity entries.
+// A capacity of zero or less means the cache is unbounded.
+func NewLRU[K comparable, V any](capacity int, onEvict func(key K, value V), options ...LRUOption[K, V]) *LRU[K, V] {
+	l := &LRU[K, V]{
 		capacity: capacity,
-		cache:    make(map[K]*NodeKV[K, V], capacity),
+		cache:    make(map[K]*NodeKV[K, V], max(capacity, 0)),
 		onEvict:  onEvict,
 	}
+
+	for _, opt := range options {
+		opt(l)
+	}
+
+	return l
 }
 
 func (l *LRU[K, V]) Get(key K) (V, bool) {
@@ -59,13 +82,17 @@ func (l *LRU[K, V]) ReverseIterator() iter.Seq2[K, V] {
 }
 
 func (l *LRU[K, V]) Put(key K, value V) {
+	if l.pressure != nil && l.pressure.due() && l.pressure.exceeded() {
+		l.Shrink(l.pressure.fraction)
+	}
+
 	if node, ok := l.cache[key]; ok {
 		node.value = value
 		l.moveToFront(node)
 		return
 	}
 
-	if l.Size() == l.capacity {
+	if l.capacity > 0 && l.Size() >= l.capacity {
 		delete(l.cache, l.tail.key)
 		node := l.tail
 		l.remove(l.tail)
@@ -85,11 +112,24 @@ func (l *LRU[K, V]) Delete(key K) {
 	}
 }
 
+// Shrink evicts the given fraction, between 0 and 1, of the least recently used entries.
+// It returns the number of evicted entries.
+func (l *LRU[K, V]) Shrink(fraction float64) int {
+	n := int(math.Ceil(float64(l.Size()) * min(max(fraction, 0), 1)))
+	for range n {
+		node := l.tail
+		delete(l.cache, node.key)
+		l.remove(node)
+		l.evict(node)
+	}
+	return n
+}
+
 func (l *LRU[K, V]) Clear() {
 	for _, node := range l.cache {
 		l.evict(node)
 	}
-	l.cache = make(map[K]*NodeKV[K, V], l.capacity)
+	l.cache = make(map[K]*NodeKV[K, V], max(l.capacity, 0))
 	l.head = nil
 	l.tail = nil
 }
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -41,3 +42,95 @@ func TestCapacity(t *testing.T) {
 	assert.Len(t, delCalls, 3) // "two" is also evicted
 	assert.Equal(t, []string{"one", "three", "two"}, delCalls)
 }
+
+func TestUnbounded(t *testing.T) {
+	lru := NewLRU[int, int](0, nil)
+	for i := range 100 {
+		lru.Put(i, i)
+	}
+	assert.Equal(t, 100, lru.Size())
+
+	v, found := lru.Get(0)
+	assert.True(t, found)
+	assert.Equal(t, 0, v)
+}
+
+func TestNegativeCapacityIsUnbounded(t *testing.T) {
+	evicted := 0
+	lru := NewLRU(-1, func(key int, value int) {
+		evicted++
+	})
+	for i := range 100 {
+		lru.Put(i, i)
+	}
+	assert.Equal(t, 100, lru.Size())
+	assert.Zero(t, evicted)
+
+	lru.Clear()
+	assert.Equal(t, 0, lru.Size())
+	assert.Equal(t, 100, evicted)
+
+	// the cache is still usable after being cleared
+	lru.Put(1, 1)
+	assert.Equal(t, 1, lru.Size())
+}
+
+func TestCapacityOne(t *testing.T) {
+	lru := NewLRU[int, int](1, nil)
+	lru.Put(1, 1)
+	lru.Put(2, 2)
+	assert.Equal(t, 1, lru.Size())
+
+	_, found := lru.Get(1)
+	assert.False(t, found)
+	v, found := lru.Get(2)
+	assert.True(t, found)
+	assert.Equal(t, 2, v)
+}
+
+func TestShrink(t *testing.T) {
+	evicted := []int{}
+	lru := NewLRU(0, func(key int, value int) {
+		evicted = append(evicted, key)
+	})
+	for i := range 10 {
+		lru.Put(i, i)
+	}
+	lru.Get(0) // 1 is now the coldest
+
+	assert.Equal(t, 3, lru.Shrink(0.25))
+	assert.Equal(t, []int{1, 2, 3}, evicted)
+	assert.Equal(t, 7, lru.Size())
+
+	assert.Equal(t, 0, lru.Shrink(0))
+	assert.Equal(t, 7, lru.Shrink(1))
+	assert.Equal(t, 0, lru.Size())
+}
+
+func TestMemoryPressure(t *testing.T) {
+	var used uint64
+	evicted := 0
+	lru := NewLRU(0, func(key int, value int) {
+		evicted++
+	}, WithLRUMemoryPressure[int, int](MemoryPressure{
+		SoftLimit: 1000,
+		Fraction:  0.5,
+		Interval:  time.Nanosecond,
+		Sample:    func() uint64 { return used },
+	}))
+
+	for i := range 10 {
+		lru.Put(i, i)
+	}
+	assert.Equal(t, 0, evicted)
+
+	used = 1001
+	time.Sleep(time.Millisecond)
+	lru.Put(10, 10)
+	assert.Equal(t, 5, evicted)
+	assert.Equal(t, 6, lru.Size())
+	_, ok := lru.Get(0)
+	assert.False(t, ok)
+	_, ok = lru.Get(10)
+	assert.True(t, ok)
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+	"time"
+)
+
+const (
+	defaultShedFraction   = 0.1
+	defaultSampleInterval = time.Second
+	totalMemoryMetric     = "/memory/classes/total:bytes"
+	releasedMemoryMetric  = "/memory/classes/heap/released:bytes"
+)
+
+// MemoryPressure configures the shedding of cache entries when the process uses too much memory.
+// Whenever a sample exceeds the limit, the coldest entries are evicted.
+type MemoryPressure struct {
+	// SoftLimit is the memory in use, in bytes, above which entries are shed.
+	// When zero, the limit set with GOMEMLIMIT or debug.SetMemoryLimit is used.
+	// If there is no limit at all, no entries are shed.
+	SoftLimit uint64
+	// Fraction of the entries shed each time the limit is exceeded. Defaults to 0.1
+	Fraction float64
+	// Interval is the minimum time between samples. Defaults to 1s
+	Interval time.Duration
+	// Sample returns the memory in use, in bytes. Defaults to the memory mapped by the Go runtime
+	// that was not released to the OS, the same amount the runtime compares against GOMEMLIMIT.
+	Sample func() uint64
+}
+
+type memoryMonitor struct {
+	limit    uint64
+	fraction float64
+	interval time.Duration
+	sample   func() uint64
+	last     time.Time
+}
+
+// newMemoryMonitor applies the defaults to the configuration.
+// It returns nil if there is no memory limit.
+func newMemoryMonitor(mp MemoryPressure) *memoryMonitor {
+	m := &memoryMonitor{
+		limit:    mp.SoftLimit,
+		fraction: mp.Fraction,
+		interval: mp.Interval,
+		sample:   mp.Sample,
+	}
+	if m.limit == 0 {
+		// a negative value only reads the current limit
+		limit := debug.SetMemoryLimit(-1)
+		if limit == math.MaxInt64 {
+			return nil
+		}
+		m.limit = uint64(limit)
+	}
+	if m.fraction <= 0 || m.fraction > 1 {
+		m.fraction = defaultShedFraction
+	}
+	if m.interval <= 0 {
+		m.interval = defaultSampleInterval
+	}
+	if m.sample == nil {
+		m.sample = memoryInUse
+	}
+	return m
+}
+
+// due returns true if enough time has passed since the last sample
+func (m *memoryMonitor) due() bool {
+	now := time.Now()
+	if now.Sub(m.last) < m.interval {
+		return false
+	}
+	m.last = now
+	return true
+}
+
+func (m *memoryMonitor) exceeded() bool {
+	return m.sample() > m.limit
+}
+
+// memoryInUse returns the memory mapped by the Go runtime minus the heap memory released to the OS
+func memoryInUse() uint64 {
+	samples := []metrics.Sample{{Name: totalMemoryMetric}, {Name: releasedMemoryMetric}}
+	metrics.Read(samples)
+	for _, s := range samples {
+		if s.Value.Kind() != metrics.KindUint64 {
+			return 0
+		}
+	}
+	return samples[0].Value.Uint64() - samples[1].Value.Uint64()
+}
@@ -0,0 +1,17 @@
+package cache
+
+import (
+	"runtime/metrics"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryInUse(t *testing.T) {
+	samples := []metrics.Sample{{Name: "/gc/heap/live:bytes"}}
+	metrics.Read(samples)
+
+	used := memoryInUse()
+	// the runtime memory includes the live heap, besides stacks, metadata and the heap not yet collected
+	assert.Greater(t, used, samples[0].Value.Uint64())
+}
@@ -0,0 +1,306 @@
+package indexedlist
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"math/rand/v2"
+)
+
+type node[T any] struct {
+	value       T
+	priority    uint64
+	size        int
+	left, right *node[T]
+}
+
+func size[T any](n *node[T]) int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *node[T]) update() {
+	n.size = 1 + size(n.left) + size(n.right)
+}
+
+// split splits the tree in the first k values and the rest
+func split[T any](n *node[T], k int) (*node[T], *node[T]) {
+	if n == nil {
+		return nil, nil
+	}
+	if size(n.left) < k {
+		l, r := split(n.right, k-size(n.left)-1)
+		n.right = l
+		n.update()
+		return n, r
+	}
+	l, r := split(n.left, k)
+	n.left = r
+	n.update()
+	return l, n
+}
+
+// merge joins two trees, where all the values of a come before the values of b
+func merge[T any](a, b *node[T]) *node[T] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if a.priority > b.priority {
+		a.right = merge(a.right, b)
+		a.update()
+		return a
+	}
+	b.left = merge(a, b.left)
+	b.update()
+	return b
+}
+
+type Option[T any] func(*List[T])
+
+// WithSeed sets the seed of the generator of the node priorities, making the shape of the tree deterministic
+func WithSeed[T any](seed uint64) Option[T] {
+	return func(l *List[T]) {
+		l.rand = rand.New(rand.NewPCG(seed, seed))
+	}
+}
+
+// List is a sequence backed by an implicit treap, a randomized balanced tree ordered by position.
+// Inserting, deleting and accessing at any index is O(log n), and so is splitting and concatenating lists.
+type List[T any] struct {
+	root *node[T]
+	rand *rand.Rand
+}
+
+func New[T any](options ...Option[T]) *List[T] {
+	l := &List[T]{}
+	for _, opt := range options {
+		opt(l)
+	}
+	return l
+}
+
+// FromSlice creates a list with the values of the slice
+func FromSlice[T any](values []T, options ...Option[T]) *List[T] {
+	l := New(options...)
+	for _, v := range values {
+		l.Add(v)
+	}
+	return l
+}
+
+// Clear empty this list, O(1)
+func (l *List[T]) Clear() {
+	l.root = nil
+}
+
+// Size returns the size of this list, O(1)
+func (l *List[T]) Size() int {
+	return size(l.root)
+}
+
+// random returns the generator of the node priorities, creating it on first use so that the zero List is usable
+func (l *List[T]) random() *rand.Rand {
+	if l.rand == nil {
+		l.rand = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	return l.rand
+}
+
+// fork returns a generator for another list, seeded from this one
+func (l *List[T]) fork() *rand.Rand {
+	r := l.random()
+	return rand.New(rand.NewPCG(r.Uint64(), r.Uint64()))
+}
+
+func (l *List[T]) newNode(data T) *node[T] {
+	return &node[T]{value: data, priority: l.random().Uint64(), size: 1}
+}
+
+// Add adds a value to the tail of the list, O(log n)
+func (l *List[T]) Add(data T) {
+	l.root = merge(l.root, l.newNode(data))
+}
+
+// AddFirst adds a value to the beginning (head) of this list, O(log n)
+func (l *List[T]) AddFirst(data T) {
+	l.root = merge(l.newNode(data), l.root)
+}
+
+// AddAt adds a value at a specified index, between 0 and the size, O(log n)
+func (l *List[T]) AddAt(index int, data T) error {
+	if index < 0 || index > l.Size() {
+		return fmt.Errorf("index out of bounds [0 - %d]: %d", l.Size(), index)
+	}
+	left, right := split(l.root, index)
+	l.root = merge(merge(left, l.newNode(data)), right)
+	return nil
+}
+
+// Set replaces the value at a specified index, O(log n)
+func (l *List[T]) Set(index int, data T) error {
+	n, err := l.find(index)
+	if err != nil {
+		return err
+	}
+	n.value = data
+	return nil
+}
+
+// Get returns the value at a specified index, O(log n)
+func (l *List[T]) Get(index int) (T, error) {
+	n, err := l.find(index)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return n.value, nil
+}
+
+// DeleteAt removes the value at a specified index, O(log n)
+func (l *List[T]) DeleteAt(index int) (T, error) {
+	if err := l.checkIndex(index); err != nil {
+		var zero T
+		return zero, err
+	}
+	left, right := split(l.root, index)
+	n, right := split(right, 1)
+	l.root = merge(left, right)
+	return n.value, nil
+}
+
+// PeekFirst checks the value at the head if it exists, O(log n)
+func (l *List[T]) PeekFirst() (T, error) {
+	if l.root == nil {
+		var zero T
+		return zero, errors.New("empty list")
+	}
+	return l.Get(0)
+}
+
+// PeekLast checks the value at the tail if it exists, O(log n)
+func (l *List[T]) PeekLast() (T, error) {
+	if l.root == nil {
+		var zero T
+		return zero, errors.New("empty list")
+	}
+	return l.Get(l.Size() - 1)
+}
+
+// Split moves the values from the index onwards to a new list, O(log n)
+func (l *List[T]) Split(index int) (*List[T], error) {
+	if index < 0 || index > l.Size() {
+		return nil, fmt.Errorf("index out of bounds [0 - %d]: %d", l.Size(), index)
+	}
+	left, right := split(l.root, index)
+	l.root = left
+	return &List[T]{root: right, rand: l.fork()}, nil
+}
+
+// Concat moves all the values of the other list to the tail of this list, leaving the other list empty, O(log n)
+func (l *List[T]) Concat(other *List[T]) {
+	if other == l {
+		return
+	}
+	l.root = merge(l.root, other.root)
+	other.root = nil
+}
+
+func (l *List[T]) checkIndex(index int) error {
+	if index < 0 || index >= l.Size() {
+		return fmt.Errorf("index out of bounds [0 - %d): %d", l.Size(), index)
+	}
+	return nil
+}
+
+func (l *List[T]) find(index int) (*node[T], error) {
+	if err := l.checkIndex(index); err != nil {
+		return nil, err
+	}
+	n := l.root
+	for {
+		s := size(n.left)
+		switch {
+		case index < s:
+			n = n.left
+		case index > s:
+			index -= s + 1
+			n = n.right
+		default:
+			return n, nil
+		}
+	}
+}
+
+// Values iterates over the values from the head to the tail, O(n)
+func (l *List[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range l.Entries() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Entries iterates over the positions and values from the head to the tail, O(n)
+func (l *List[T]) Entries() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		var stack []*node[T]
+		i := 0
+		for n := l.root; n != nil || len(stack) > 0; {
+			for ; n != nil; n = n.left {
+				stack = append(stack, n)
+			}
+			n = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if !yield(i, n.value) {
+				return
+			}
+			i++
+			n = n.right
+		}
+	}
+}
+
+// Backward iterates over the positions and values from the tail to the head, O(n)
+func (l *List[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		var stack []*node[T]
+		i := l.Size() - 1
+		for n := l.root; n != nil || len(stack) > 0; {
+			for ; n != nil; n = n.right {
+				stack = append(stack, n)
+			}
+			n = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if !yield(i, n.value) {
+				return
+			}
+			i--
+			n = n.left
+		}
+	}
+}
+
+// Clone returns a copy of the list, O(n)
+func (l *List[T]) Clone() *List[T] {
+	return &List[T]{
+		root: clone(l.root),
+		rand: l.fork(),
+	}
+}
+
+func clone[T any](n *node[T]) *node[T] {
+	if n == nil {
+		return nil
+	}
+	c := *n
+	c.left = clone(n.left)
+	c.right = clone(n.right)
+	return &c
+}
@@ -0,0 +1,174 @@
+package indexedlist_test
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quintans/ds/collections/indexedlist"
+)
+
+func TestNew(t *testing.T) {
+	l := indexedlist.New[int]()
+	assert.Equal(t, 0, l.Size())
+	_, err := l.PeekFirst()
+	assert.Error(t, err)
+	_, err = l.PeekLast()
+	assert.Error(t, err)
+	_, err = l.Get(0)
+	assert.Error(t, err)
+	_, err = l.DeleteAt(0)
+	assert.Error(t, err)
+}
+
+func TestZeroValue(t *testing.T) {
+	var l indexedlist.List[int]
+	require.NoError(t, l.AddAt(0, 2))
+	l.AddFirst(1)
+	l.Add(3)
+	assert.Equal(t, []int{1, 2, 3}, slices.Collect(l.Values()))
+
+	var empty indexedlist.List[int]
+	clone := empty.Clone()
+	clone.Add(1)
+	assert.Equal(t, 1, clone.Size())
+
+	var whole indexedlist.List[int]
+	right, err := whole.Split(0)
+	require.NoError(t, err)
+	right.Add(1)
+	assert.Equal(t, 1, right.Size())
+}
+
+func TestAddGetSet(t *testing.T) {
+	l := indexedlist.New(indexedlist.WithSeed[string](1))
+	l.Add("b")
+	l.AddFirst("a")
+	l.Add("d")
+	require.NoError(t, l.AddAt(2, "c"))
+	require.NoError(t, l.AddAt(4, "e"))
+	assert.Error(t, l.AddAt(6, "x"))
+	assert.Error(t, l.AddAt(-1, "x"))
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, slices.Collect(l.Values()))
+
+	v, err := l.Get(3)
+	require.NoError(t, err)
+	assert.Equal(t, "d", v)
+	require.NoError(t, l.Set(3, "D"))
+	v, err = l.Get(3)
+	require.NoError(t, err)
+	assert.Equal(t, "D", v)
+	assert.Error(t, l.Set(5, "x"))
+
+	first, err := l.PeekFirst()
+	require.NoError(t, err)
+	assert.Equal(t, "a", first)
+	last, err := l.PeekLast()
+	require.NoError(t, err)
+	assert.Equal(t, "e", last)
+
+	v, err = l.DeleteAt(1)
+	require.NoError(t, err)
+	assert.Equal(t, "b", v)
+	assert.Equal(t, []string{"a", "c", "D", "e"}, slices.Collect(l.Values()))
+}
+
+func TestIteration(t *testing.T) {
+	l := indexedlist.FromSlice([]string{"a", "b", "c"})
+	var indexes []int
+	var values []string
+	for i, v := range l.Entries() {
+		indexes = append(indexes, i)
+		values = append(values, v)
+	}
+	assert.Equal(t, []int{0, 1, 2}, indexes)
+	assert.Equal(t, []string{"a", "b", "c"}, values)
+
+	indexes, values = nil, nil
+	for i, v := range l.Backward() {
+		indexes = append(indexes, i)
+		values = append(values, v)
+	}
+	assert.Equal(t, []int{2, 1, 0}, indexes)
+	assert.Equal(t, []string{"c", "b", "a"}, values)
+
+	for v := range l.Values() {
+		assert.Equal(t, "a", v)
+		break
+	}
+}
+
+func TestSplitConcat(t *testing.T) {
+	l := indexedlist.FromSlice([]int{0, 1, 2, 3, 4, 5})
+	right, err := l.Split(4)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3}, slices.Collect(l.Values()))
+	assert.Equal(t, []int{4, 5}, slices.Collect(right.Values()))
+
+	_, err = l.Split(5)
+	assert.Error(t, err)
+
+	right.Add(6)
+	right.Concat(l)
+	assert.Equal(t, []int{4, 5, 6, 0, 1, 2, 3}, slices.Collect(right.Values()))
+	assert.Equal(t, 0, l.Size())
+	right.Concat(right)
+	assert.Equal(t, 7, right.Size())
+
+	empty, err := right.Split(7)
+	require.NoError(t, err)
+	assert.Equal(t, 0, empty.Size())
+	all, err := right.Split(0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, right.Size())
+	assert.Equal(t, 7, all.Size())
+}
+
+func TestClone(t *testing.T) {
+	l := indexedlist.FromSlice([]int{1, 2, 3})
+	c := l.Clone()
+	require.NoError(t, c.Set(0, 10))
+	c.Add(4)
+	assert.Equal(t, []int{1, 2, 3}, slices.Collect(l.Values()))
+	assert.Equal(t, []int{10, 2, 3, 4}, slices.Collect(c.Values()))
+	l.Clear()
+	assert.Equal(t, 0, l.Size())
+}
+
+func TestRandomAgainstSlice(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	l := indexedlist.New(indexedlist.WithSeed[int](3))
+	var want []int
+	for i := range 20000 {
+		switch op := r.IntN(10); {
+		case op < 5:
+			index := r.IntN(len(want) + 1)
+			require.NoError(t, l.AddAt(index, i))
+			want = slices.Insert(want, index, i)
+		case len(want) == 0:
+		case op < 8:
+			index := r.IntN(len(want))
+			v, err := l.DeleteAt(index)
+			require.NoError(t, err)
+			require.Equal(t, want[index], v)
+			want = slices.Delete(want, index, index+1)
+		case op < 9:
+			index := r.IntN(len(want))
+			v, err := l.Get(index)
+			require.NoError(t, err)
+			require.Equal(t, want[index], v)
+		default:
+			// split and concat back
+			index := r.IntN(len(want) + 1)
+			right, err := l.Split(index)
+			require.NoError(t, err)
+			require.Equal(t, index, l.Size())
+			l.Concat(right)
+		}
+		require.Equal(t, len(want), l.Size())
+	}
+	assert.Equal(t, want, slices.Collect(l.Values()))
+}
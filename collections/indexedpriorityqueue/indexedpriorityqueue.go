@@ -3,6 +3,7 @@ package indexedpriorityqueue
 import (
 	"cmp"
 	"container/heap"
+	"iter"
 )
 
 // Comparator defines the function signature for comparing two elements.
@@ -131,12 +132,14 @@ func (ipq *IndexedPriorityQueue[T, K]) Get(key K) (T, bool) {
 }
 
 // Enqueue adds an element to the priority queue.
-// If an element with the same key already exists it is replaced by the new one.
+// If an element with the same key already exists it is replaced by the new one
+// and its position in the queue is updated to reflect the new priority.
 func (ipq *IndexedPriorityQueue[T, K]) Enqueue(value T) {
 	key := ipq.keyFunc(value)
 	if existingItem, ok := ipq.index[key]; ok {
-		// Key exists, replace the existing item's value
+		// Key exists, replace the existing item's value and restore the heap order
 		existingItem.value = value
+		heap.Fix(ipq.pq, existingItem.index)
 	} else {
 		// Key doesn't exist, add a new item
 		newItem := &item[T, K]{
@@ -178,3 +181,15 @@ func (ipq *IndexedPriorityQueue[T, K]) Remove(key K) (T, bool) {
 
 	return removedItem.value, true
 }
+
+// Values returns an iterator over the elements in the queue, in no particular order.
+// The queue must not be modified during the iteration.
+func (ipq *IndexedPriorityQueue[T, K]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, it := range ipq.pq.items {
+			if !yield(it.value) {
+				return
+			}
+		}
+	}
+}
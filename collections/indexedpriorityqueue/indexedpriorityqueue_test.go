@@ -1,6 +1,8 @@
 package indexedpriorityqueue
 
 import (
+	"math/rand/v2"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -399,3 +401,98 @@ func TestRemoveRoot(t *testing.T) {
 	_, okGet3 := q.Get(3)
 	assert.True(t, okGet3)
 }
+
+// scoredItem has a priority that is independent of its key.
+type scoredItem struct {
+	name  string
+	score int
+}
+
+// Test that replacing an element with a different priority moves it in the queue
+func TestEnqueueReprioritizes(t *testing.T) {
+	q := New(func(a, b scoredItem) int {
+		return a.score - b.score
+	}, func(item scoredItem) string {
+		return item.name
+	})
+	q.Enqueue(scoredItem{"a", 1})
+	q.Enqueue(scoredItem{"b", 2})
+	q.Enqueue(scoredItem{"c", 3})
+
+	q.Enqueue(scoredItem{"a", 10}) // demote the root
+	peeked, _ := q.Peek()
+	assert.Equal(t, "b", peeked.name, "Demoted root should no longer be first")
+
+	q.Enqueue(scoredItem{"c", 0}) // promote a leaf
+	peeked, _ = q.Peek()
+	assert.Equal(t, "c", peeked.name, "Promoted leaf should be first")
+
+	var order []string
+	for q.Len() > 0 {
+		it, _ := q.Dequeue()
+		order = append(order, it.name)
+	}
+	assert.Equal(t, []string{"c", "b", "a"}, order)
+}
+
+func TestValues(t *testing.T) {
+	q := newTestQueue()
+	q.Enqueue(testItem{id: 3, value: "C"})
+	q.Enqueue(testItem{id: 1, value: "A"})
+	q.Enqueue(testItem{id: 2, value: "B"})
+
+	var values []string
+	for v := range q.Values() {
+		values = append(values, v.value)
+	}
+	assert.ElementsMatch(t, []string{"A", "B", "C"}, values)
+	assert.Equal(t, 3, q.Len(), "Values should not change queue length")
+}
+
+// Test that the heap order holds after many replacements with random priorities
+func TestEnqueueReprioritizesMany(t *testing.T) {
+	q := New(func(a, b scoredItem) int {
+		return a.score - b.score
+	}, func(item scoredItem) string {
+		return item.name
+	})
+	r := rand.New(rand.NewPCG(1, 2))
+	scores := map[string]int{}
+	for range 1000 {
+		name := strconv.Itoa(r.IntN(50))
+		score := r.IntN(1000)
+		scores[name] = score
+		q.Enqueue(scoredItem{name, score})
+	}
+	assert.Equal(t, len(scores), q.Len())
+
+	for name, score := range scores {
+		it, ok := q.Get(name)
+		assert.True(t, ok)
+		assert.Equal(t, score, it.score, "Get should return the replacing element")
+	}
+
+	last := -1
+	for q.Len() > 0 {
+		it, _ := q.Dequeue()
+		assert.GreaterOrEqual(t, it.score, last, "Elements should be dequeued in priority order")
+		assert.Equal(t, scores[it.name], it.score)
+		last = it.score
+	}
+}
+
+func TestValuesEmptyAndBreak(t *testing.T) {
+	q := newTestQueue()
+	for range q.Values() {
+		assert.Fail(t, "Empty queue should have no values")
+	}
+
+	q.Enqueue(testItem{id: 1, value: "A"})
+	q.Enqueue(testItem{id: 2, value: "B"})
+	count := 0
+	for range q.Values() {
+		count++
+		break
+	}
+	assert.Equal(t, 1, count, "Values should stop when the loop breaks")
+}
@@ -0,0 +1,154 @@
+package linkedlist
+
+type position int
+
+const (
+	beforeHead position = iota
+	onElement
+	removed
+	afterTail
+)
+
+// Cursor traverses a list in both directions while the list is modified.
+// Removing the current element with the cursor keeps its place, so that Next and Prev move to the neighbours it had.
+type Cursor[T any] struct {
+	list *List[T]
+	pos  position
+	cur  *Element[T]
+	// next and prev are the neighbours of the current element when it was removed
+	next, prev *Element[T]
+}
+
+// Cursor returns a cursor positioned before the head, so that the first call to Next moves it to the head
+func (l *List[T]) Cursor() *Cursor[T] {
+	return &Cursor[T]{list: l, pos: beforeHead}
+}
+
+// CursorAt returns a cursor positioned at e.
+// If e is not an element of this list, the cursor is positioned after the tail.
+func (l *List[T]) CursorAt(e *Element[T]) *Cursor[T] {
+	if e == nil || e.list() != l {
+		return &Cursor[T]{list: l, pos: afterTail}
+	}
+	return &Cursor[T]{list: l, pos: onElement, cur: e}
+}
+
+// Next moves the cursor to the next element, returning false if there is none
+func (c *Cursor[T]) Next() bool {
+	var next *Element[T]
+	switch c.pos {
+	case beforeHead:
+		next = c.list.head
+	case onElement:
+		next = c.cur.next
+	case removed:
+		next = c.next
+	}
+	return c.moveTo(next, afterTail)
+}
+
+// Prev moves the cursor to the previous element, returning false if there is none
+func (c *Cursor[T]) Prev() bool {
+	var prev *Element[T]
+	switch c.pos {
+	case afterTail:
+		prev = c.list.tail
+	case onElement:
+		prev = c.cur.prev
+	case removed:
+		prev = c.prev
+	}
+	return c.moveTo(prev, beforeHead)
+}
+
+// moveTo moves to e, or to the end if e is not an element of the list
+func (c *Cursor[T]) moveTo(e *Element[T], end position) bool {
+	c.next, c.prev = nil, nil
+	if e == nil || e.list() != c.list {
+		c.pos = end
+		c.cur = nil
+		return false
+	}
+	c.pos = onElement
+	c.cur = e
+	return true
+}
+
+// Element returns the current element, or nil if the cursor is not on one
+func (c *Cursor[T]) Element() *Element[T] {
+	if c.pos != onElement {
+		return nil
+	}
+	return c.cur
+}
+
+// Value returns the value of the current element, or the zero value if the cursor is not on one
+func (c *Cursor[T]) Value() T {
+	if c.pos != onElement {
+		var zero T
+		return zero
+	}
+	return c.cur.value
+}
+
+// Remove removes the current element from the list, returning false if the cursor is not on one
+func (c *Cursor[T]) Remove() bool {
+	if c.pos != onElement || c.cur.list() != c.list {
+		return false
+	}
+	c.next, c.prev = c.cur.next, c.cur.prev
+	c.list.cut(c.cur)
+	c.cur = nil
+	c.pos = removed
+	return true
+}
+
+// InsertBefore inserts a value before the current element and returns its element.
+// Before the head it does nothing and returns nil, and after the tail it adds the value to the tail.
+// If the current element was removed, the value takes its place.
+func (c *Cursor[T]) InsertBefore(data T) *Element[T] {
+	switch c.pos {
+	case onElement:
+		return c.list.InsertBefore(c.cur, data)
+	case afterTail:
+		return c.list.Add(data)
+	case removed:
+		e := c.insertAtGap(data)
+		// the new element is behind the cursor
+		c.prev = e
+		return e
+	}
+	return nil
+}
+
+// InsertAfter inserts a value after the current element and returns its element.
+// After the tail it does nothing and returns nil, and before the head it adds the value to the head.
+// If the current element was removed, the value takes its place.
+func (c *Cursor[T]) InsertAfter(data T) *Element[T] {
+	switch c.pos {
+	case onElement:
+		return c.list.InsertAfter(c.cur, data)
+	case beforeHead:
+		return c.list.AddFirst(data)
+	case removed:
+		e := c.insertAtGap(data)
+		// the new element is ahead of the cursor
+		c.next = e
+		return e
+	}
+	return nil
+}
+
+// insertAtGap inserts the value where the removed current element was
+func (c *Cursor[T]) insertAtGap(data T) *Element[T] {
+	switch {
+	case c.prev != nil && c.prev.list() == c.list:
+		return c.list.InsertAfter(c.prev, data)
+	case c.next != nil && c.next.list() == c.list:
+		return c.list.InsertBefore(c.next, data)
+	case c.prev == nil:
+		return c.list.AddFirst(data)
+	default:
+		return c.list.Add(data)
+	}
+}
@@ -0,0 +1,102 @@
+package linkedlist_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/quintans/ds/collections/linkedlist"
+)
+
+func TestCursorTraversal(t *testing.T) {
+	l := linkedlist.FromSlice([]int{1, 2, 3})
+	c := l.Cursor()
+	assert.Nil(t, c.Element())
+	assert.False(t, c.Prev())
+
+	var forward []int
+	for c.Next() {
+		forward = append(forward, c.Value())
+	}
+	assert.Equal(t, []int{1, 2, 3}, forward)
+	assert.Nil(t, c.Element())
+	assert.Equal(t, 0, c.Value())
+
+	var backward []int
+	for c.Prev() {
+		backward = append(backward, c.Value())
+	}
+	assert.Equal(t, []int{3, 2, 1}, backward)
+
+	c = l.CursorAt(l.Head().Next())
+	assert.Equal(t, 2, c.Value())
+	assert.True(t, c.Next())
+	assert.Equal(t, 3, c.Value())
+
+	other := linkedlist.FromSlice([]int{9})
+	c = l.CursorAt(other.Head())
+	assert.Nil(t, c.Element())
+	assert.True(t, c.Prev())
+	assert.Equal(t, 3, c.Value())
+}
+
+func TestCursorRemove(t *testing.T) {
+	l := linkedlist.FromSlice([]int{1, 2, 3, 4, 5, 6}, linkedlist.WithInvariantChecks[int]())
+	c := l.Cursor()
+	for c.Next() {
+		if c.Value()%2 == 0 {
+			assert.True(t, c.Remove())
+			assert.False(t, c.Remove())
+			assert.Nil(t, c.Element())
+		}
+	}
+	assert.Equal(t, []int{1, 3, 5}, collectValues(l))
+
+	// removing goes back to the previous element
+	c = l.CursorAt(l.Tail())
+	c.Remove()
+	assert.True(t, c.Prev())
+	assert.Equal(t, 3, c.Value())
+	assert.Equal(t, []int{1, 3}, collectValues(l))
+
+	// removing every element
+	c = l.Cursor()
+	for c.Next() {
+		c.Remove()
+	}
+	assert.Equal(t, 0, l.Size())
+}
+
+func TestCursorInsert(t *testing.T) {
+	l := linkedlist.FromSlice([]int{1, 3, 5}, linkedlist.WithInvariantChecks[int]())
+	c := l.Cursor()
+	assert.Equal(t, 0, c.InsertAfter(0).Value())
+	assert.Nil(t, c.InsertBefore(-1))
+
+	var visited []int
+	for c.Next() {
+		v := c.Value()
+		visited = append(visited, v)
+		if v%2 == 1 && v < 5 {
+			c.InsertAfter(v + 1)
+			// skip the inserted value
+			c.Next()
+		}
+	}
+	assert.Equal(t, []int{0, 1, 3, 5}, visited)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5}, collectValues(l))
+
+	assert.Equal(t, 6, c.InsertBefore(6).Value())
+	assert.Nil(t, c.InsertAfter(7))
+
+	// a removed element can be replaced
+	c = l.CursorAt(l.Head().Next())
+	c.Remove()
+	c.InsertBefore(10)
+	c.InsertAfter(11)
+	assert.Equal(t, []int{0, 10, 11, 2, 3, 4, 5, 6}, collectValues(l))
+	assert.True(t, c.Next())
+	assert.Equal(t, 11, c.Value())
+	assert.True(t, c.Prev())
+	assert.Equal(t, 10, c.Value())
+}
@@ -4,12 +4,40 @@ import (
 	"errors"
 	"fmt"
 	"iter"
+	"slices"
 )
 
 type Element[T any] struct {
 	value      T
 	next, prev *Element[T]
-	list       *List[T]
+	owner      *owner[T]
+}
+
+// owner identifies the list holding an element.
+// Splicing merges the owner of the spliced list with the owner of the receiving list, like in a union-find,
+// so that the ownership of the moved elements is reassigned without visiting them.
+// The merge is by rank, keeping the paths O(log n) without compressing them,
+// so that reading the owner never writes and concurrent readers do not race.
+type owner[T any] struct {
+	list   *List[T]
+	parent *owner[T]
+	rank   int
+}
+
+// find returns the root owner
+func (o *owner[T]) find() *owner[T] {
+	for o.parent != nil {
+		o = o.parent
+	}
+	return o
+}
+
+// list returns the list holding the element, or nil if the element was removed
+func (e *Element[T]) list() *List[T] {
+	if e.owner == nil {
+		return nil
+	}
+	return e.owner.find().list
 }
 
 func (e *Element[T]) Value() T {
@@ -24,22 +52,55 @@ func (e *Element[T]) Previous() *Element[T] {
 	return e.prev
 }
 
+// Remove removes the element from its list, O(1).
+// It does nothing if the element was already removed.
 func (e *Element[T]) Remove() {
-	e.list.cut(e)
+	if l := e.list(); l != nil {
+		l.cut(e)
+	}
+}
+
+type Option[T any] func(*List[T])
+
+// WithInvariantChecks validates the links, the ownership and the size of the list after every mutation,
+// panicking on the first inconsistency. Every mutation becomes O(n), so it is meant for tests and debugging.
+func WithInvariantChecks[T any]() Option[T] {
+	return func(l *List[T]) {
+		l.checked = true
+	}
 }
 
 type List[T any] struct {
-	size int
-	head *Element[T]
-	tail *Element[T]
+	size    int
+	head    *Element[T]
+	tail    *Element[T]
+	owner   *owner[T]
+	checked bool
 }
 
-func New[T any]() *List[T] {
+func New[T any](options ...Option[T]) *List[T] {
 	l := &List[T]{}
+	for _, opt := range options {
+		opt(l)
+	}
 	l.Clear()
 	return l
 }
 
+// FromSeq creates a list with the values of the sequence
+func FromSeq[T any](seq iter.Seq[T], options ...Option[T]) *List[T] {
+	l := New(options...)
+	for v := range seq {
+		l.Add(v)
+	}
+	return l
+}
+
+// FromSlice creates a list with the values of the slice
+func FromSlice[T any](values []T, options ...Option[T]) *List[T] {
+	return FromSeq(slices.Values(values), options...)
+}
+
 func (l *List[T]) Head() *Element[T] {
 	return l.head
 }
@@ -50,9 +111,23 @@ func (l *List[T]) Tail() *Element[T] {
 
 // Clear empty this linked list, O(1)
 func (l *List[T]) Clear() {
+	if l.owner != nil {
+		// detaches the current elements
+		l.owner.list = nil
+		l.owner = nil
+	}
 	l.head = nil
 	l.tail = nil
 	l.size = 0
+	l.verify()
+}
+
+// own returns the owner of the elements of this list
+func (l *List[T]) own() *owner[T] {
+	if l.owner == nil {
+		l.owner = &owner[T]{list: l}
+	}
+	return l.owner
 }
 
 // Size returns the size of this linked list
@@ -62,12 +137,10 @@ func (l *List[T]) Size() int {
 
 // AddLast adds elements to the tail of the linked list, O(1)
 func (l *List[T]) Add(data T) *Element[T] {
-	elem := &Element[T]{value: data, list: l}
+	elem := &Element[T]{value: data}
 
 	if l.tail == nil {
-		l.head = elem
-		l.tail = elem
-		l.size++
+		l.insertFirst(elem)
 	} else {
 		l.insertAfter(l.tail, elem)
 	}
@@ -77,17 +150,24 @@ func (l *List[T]) Add(data T) *Element[T] {
 
 // AddFirst adds elements to the beginning (head) of this linked list, O(1)
 func (l *List[T]) AddFirst(data T) *Element[T] {
-	elem := &Element[T]{value: data, list: l}
+	elem := &Element[T]{value: data}
 	if l.head == nil {
-		l.head = elem
-		l.tail = elem
-		l.size++
+		l.insertFirst(elem)
 	} else {
 		l.insertBefore(l.head, elem)
 	}
 	return elem
 }
 
+// insertFirst adds the element to an empty list
+func (l *List[T]) insertFirst(e *Element[T]) {
+	l.head = e
+	l.tail = e
+	e.owner = l.own()
+	l.size++
+	l.verify()
+}
+
 func (l *List[T]) insertAfter(at, e *Element[T]) {
 	e.prev = at
 	e.next = at.next
@@ -99,9 +179,10 @@ func (l *List[T]) insertAfter(at, e *Element[T]) {
 	}
 	at.next = e
 
-	e.list = l
+	e.owner = l.own()
 
 	l.size++
+	l.verify()
 }
 
 func (l *List[T]) insertBefore(at, e *Element[T]) {
@@ -115,19 +196,239 @@ func (l *List[T]) insertBefore(at, e *Element[T]) {
 	}
 	at.prev = e
 
-	e.list = l
+	e.owner = l.own()
 
 	l.size++
+	l.verify()
+}
+
+// InsertAfter inserts a new element with the value right after mark and returns it, O(1).
+// If mark is not an element of this list, the list is not modified and nil is returned.
+func (l *List[T]) InsertAfter(mark *Element[T], data T) *Element[T] {
+	if mark == nil || mark.list() != l {
+		return nil
+	}
+	elem := &Element[T]{value: data}
+	l.insertAfter(mark, elem)
+	return elem
+}
+
+// InsertBefore inserts a new element with the value right before mark and returns it, O(1).
+// If mark is not an element of this list, the list is not modified and nil is returned.
+func (l *List[T]) InsertBefore(mark *Element[T], data T) *Element[T] {
+	if mark == nil || mark.list() != l {
+		return nil
+	}
+	elem := &Element[T]{value: data}
+	l.insertBefore(mark, elem)
+	return elem
 }
 
 // MoveToLast moves element to the tail of the linked list, O(1)
 func (l *List[T]) MoveToLast(e *Element[T]) {
-	l.moveAfter(l.tail, e)
+	l.MoveAfter(e, l.tail)
 }
 
 // MoveToFirst moves element to the beginning (head) of this linked list, O(1)
 func (l *List[T]) MoveToFirst(e *Element[T]) {
-	l.moveBefore(l.head, e)
+	l.MoveBefore(e, l.head)
+}
+
+// MoveAfter moves the element right after mark, O(1).
+// If e or mark are not elements of this list, or e == mark, the list is not modified.
+func (l *List[T]) MoveAfter(e, mark *Element[T]) {
+	if e == nil || mark == nil || e == mark || e.list() != l || mark.list() != l {
+		return
+	}
+	l.moveAfter(mark, e)
+}
+
+// MoveBefore moves the element right before mark, O(1).
+// If e or mark are not elements of this list, or e == mark, the list is not modified.
+func (l *List[T]) MoveBefore(e, mark *Element[T]) {
+	if e == nil || mark == nil || e == mark || e.list() != l || mark.list() != l {
+		return
+	}
+	l.moveBefore(mark, e)
+}
+
+// PushBackList adds a copy of the values of the other list to the tail of this list, O(m).
+// The lists can be the same.
+func (l *List[T]) PushBackList(other *List[T]) {
+	for i, e := other.Size(), other.Head(); i > 0; i, e = i-1, e.next {
+		l.Add(e.value)
+	}
+}
+
+// PushFrontList adds a copy of the values of the other list to the beginning (head) of this list, keeping their order, O(m).
+// The lists can be the same.
+func (l *List[T]) PushFrontList(other *List[T]) {
+	for i, e := other.Size(), other.Tail(); i > 0; i, e = i-1, e.prev {
+		l.AddFirst(e.value)
+	}
+}
+
+// SpliceAfter moves all the elements of the other list right after mark, leaving the other list empty, O(1).
+// If mark is nil the elements are moved to the beginning (head) of this list.
+// If mark is not an element of this list, or the lists are the same, nothing is moved.
+func (l *List[T]) SpliceAfter(mark *Element[T], other *List[T]) {
+	if other == l || other.size == 0 || (mark != nil && mark.list() != l) {
+		return
+	}
+
+	first, last := other.head, other.tail
+	var next *Element[T]
+	if mark != nil {
+		next = mark.next
+		mark.next = first
+	} else {
+		next = l.head
+		l.head = first
+	}
+	first.prev = mark
+	last.next = next
+	if next != nil {
+		next.prev = last
+	} else {
+		l.tail = last
+	}
+	l.size += other.size
+
+	l.adopt(other)
+	l.verify()
+}
+
+// adopt makes all the elements of the other list belong to this list, O(1), leaving the other list empty.
+// The elements must have already been linked into this list.
+func (l *List[T]) adopt(other *List[T]) {
+	root, child := l.own(), other.owner
+	if child.rank > root.rank {
+		root, child = child, root
+	} else if child.rank == root.rank {
+		root.rank++
+	}
+	child.list = nil
+	child.parent = root
+	root.list = l
+	l.owner = root
+	other.owner = nil
+	other.head = nil
+	other.tail = nil
+	other.size = 0
+	other.verify()
+}
+
+// Sort sorts the list in place with a stable bottom-up merge sort, O(n log n).
+// The elements are relinked, not copied, so they stay valid.
+func (l *List[T]) Sort(cmp func(a, b T) int) {
+	if l.size < 2 {
+		return
+	}
+
+	head := l.head
+	for width := 1; ; width *= 2 {
+		var first, last *Element[T]
+		merges := 0
+		p := head
+		for p != nil {
+			merges++
+			// q is the start of the right run
+			q := p
+			pSize := 0
+			for pSize < width && q != nil {
+				pSize++
+				q = q.next
+			}
+			qSize := width
+			for pSize > 0 || (qSize > 0 && q != nil) {
+				var e *Element[T]
+				if pSize == 0 || (qSize > 0 && q != nil && cmp(q.value, p.value) < 0) {
+					e, q = q, q.next
+					qSize--
+				} else {
+					e, p = p, p.next
+					pSize--
+				}
+				if last == nil {
+					first = e
+				} else {
+					last.next = e
+				}
+				e.prev = last
+				last = e
+			}
+			p = q
+		}
+		last.next = nil
+		head = first
+		if merges == 1 {
+			l.head = first
+			l.tail = last
+			break
+		}
+	}
+	l.verify()
+}
+
+// IsSorted reports whether the list is sorted, O(n)
+func (l *List[T]) IsSorted(cmp func(a, b T) int) bool {
+	for e := l.head; e != nil && e.next != nil; e = e.next {
+		if cmp(e.next.value, e.value) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// InsertSorted inserts the value in a sorted list, after any equal values, and returns its element, O(n).
+// Values are searched from the tail, so adding values in order is O(1).
+func (l *List[T]) InsertSorted(data T, cmp func(a, b T) int) *Element[T] {
+	elem := &Element[T]{value: data}
+	at := l.tail
+	for at != nil && cmp(at.value, data) > 0 {
+		at = at.prev
+	}
+	switch {
+	case l.head == nil:
+		l.insertFirst(elem)
+	case at == nil:
+		l.insertBefore(l.head, elem)
+	default:
+		l.insertAfter(at, elem)
+	}
+	return elem
+}
+
+// MergeSorted merges the elements of the other sorted list into this sorted list, O(n+m), leaving the other list empty.
+// The merge is stable: equal values of this list come first.
+func (l *List[T]) MergeSorted(other *List[T], cmp func(a, b T) int) {
+	if other == l || other.size == 0 {
+		return
+	}
+
+	p, q := l.head, other.head
+	var first, last *Element[T]
+	for p != nil || q != nil {
+		var e *Element[T]
+		if p == nil || (q != nil && cmp(q.value, p.value) < 0) {
+			e, q = q, q.next
+		} else {
+			e, p = p, p.next
+		}
+		if last == nil {
+			first = e
+		} else {
+			last.next = e
+		}
+		e.prev = last
+		last = e
+	}
+	l.head = first
+	l.tail = last
+	l.size += other.size
+
+	l.adopt(other)
+	l.verify()
 }
 
 func (l *List[T]) moveAfter(at, e *Element[T]) {
@@ -156,9 +457,10 @@ func (l *List[T]) cut(e *Element[T]) {
 	e.next = nil
 	e.prev = nil
 
-	e.list = nil
+	e.owner = nil
 
 	l.size--
+	l.verify()
 }
 
 // AddAt adds an element at a specified index
@@ -168,7 +470,7 @@ func (l *List[T]) AddAt(index int, data T) error {
 		return err
 	}
 
-	l.insertBefore(at, &Element[T]{value: data, list: l})
+	l.insertBefore(at, &Element[T]{value: data})
 
 	return nil
 }
@@ -286,6 +588,139 @@ func (l *List[T]) Delete(fn func(T) bool) bool {
 	return false
 }
 
+// DeleteAll removes all the values that match, returning how many were removed, O(n)
+func (l *List[T]) DeleteAll(fn func(T) bool) int {
+	count := 0
+	for e := l.head; e != nil; {
+		next := e.next
+		if fn(e.value) {
+			l.cut(e)
+			count++
+		}
+		e = next
+	}
+	return count
+}
+
+// Find returns the first element whose value matches, or nil if there is none, O(n)
+func (l *List[T]) Find(fn func(T) bool) *Element[T] {
+	for e := l.head; e != nil; e = e.next {
+		if fn(e.value) {
+			return e
+		}
+	}
+	return nil
+}
+
+// IndexOf returns the index of the first value that matches, or -1 if there is none, O(n)
+func (l *List[T]) IndexOf(fn func(T) bool) int {
+	i := 0
+	for e := l.head; e != nil; e = e.next {
+		if fn(e.value) {
+			return i
+		}
+		i++
+	}
+	return -1
+}
+
+// Reverse reverses the order of the elements in place, O(n)
+func (l *List[T]) Reverse() {
+	for e := l.head; e != nil; e = e.prev {
+		e.next, e.prev = e.prev, e.next
+	}
+	l.head, l.tail = l.tail, l.head
+	l.verify()
+}
+
+// Rotate moves the elements n positions towards the tail, wrapping the last ones to the head.
+// A negative n rotates towards the head, O(n)
+func (l *List[T]) Rotate(n int) {
+	if l.size < 2 {
+		return
+	}
+	n %= l.size
+	if n < 0 {
+		n += l.size
+	}
+	if n == 0 {
+		return
+	}
+
+	head, _ := l.findElementByIndex(l.size - n)
+	tail := head.prev
+	l.tail.next = l.head
+	l.head.prev = l.tail
+	tail.next = nil
+	head.prev = nil
+	l.head = head
+	l.tail = tail
+	l.verify()
+}
+
+// Truncate removes all the elements after the first n, O(n)
+func (l *List[T]) Truncate(n int) {
+	for l.size > max(n, 0) {
+		l.cut(l.tail)
+	}
+}
+
+// SubList returns a new list with a copy of the values between from, inclusive, and to, exclusive, O(n)
+func (l *List[T]) SubList(from, to int) (*List[T], error) {
+	if from < 0 || to > l.size || from > to {
+		return nil, fmt.Errorf("sub list [%d - %d) out of bounds [0 - %d)", from, to, l.size)
+	}
+
+	d := New[T]()
+	d.checked = l.checked
+	if from == to {
+		return d, nil
+	}
+	e, err := l.findElementByIndex(from)
+	if err != nil {
+		return nil, err
+	}
+	for i := from; i < to; i++ {
+		d.Add(e.value)
+		e = e.next
+	}
+	return d, nil
+}
+
+// Partition moves the elements whose values match into a new list, keeping their order, O(n).
+// The elements that do not match stay in this list.
+func (l *List[T]) Partition(fn func(T) bool) *List[T] {
+	d := New[T]()
+	d.checked = l.checked
+	for e := l.head; e != nil; {
+		next := e.next
+		if fn(e.value) {
+			l.cut(e)
+			if d.tail == nil {
+				d.insertFirst(e)
+			} else {
+				d.insertAfter(d.tail, e)
+			}
+		}
+		e = next
+	}
+	return d
+}
+
+// Dedup removes the consecutive values equal to the previous one, returning how many were removed, O(n)
+func (l *List[T]) Dedup(eq func(a, b T) bool) int {
+	count := 0
+	for e := l.head; e != nil && e.next != nil; {
+		if eq(e.value, e.next.value) {
+			l.cut(e.next)
+			count++
+		} else {
+			e = e.next
+		}
+	}
+	return count
+}
+
 func (l *List[T]) ReplaceAll(fn func(int, T) T) {
 	temp := l.head
 	for i := 0; i < l.size; i++ {
@@ -294,34 +729,141 @@ func (l *List[T]) ReplaceAll(fn func(int, T) T) {
 	}
 }
 
+// Values iterates over the values from the head to the tail.
+// The list can be modified during the iteration, including removing or moving the current element.
 func (l *List[T]) Values() iter.Seq[T] {
 	return func(yield func(T) bool) {
-		temp := l.head
-		for range l.size {
-			if !yield(temp.value) {
-				return
-			}
-			temp = temp.next
-		}
+		l.walk(l.head, true, func(e *Element[T]) bool {
+			return yield(e.value)
+		})
 	}
 }
 
+// Entries iterates over the positions and values from the head to the tail.
+// The list can be modified during the iteration, including removing or moving the current element.
 func (l *List[T]) Entries() iter.Seq2[int, T] {
 	return func(yield func(int, T) bool) {
-		temp := l.head
-		for i := range l.size {
-			if !yield(i, temp.value) {
+		i := 0
+		l.walk(l.head, true, func(e *Element[T]) bool {
+			i++
+			return yield(i-1, e.value)
+		})
+	}
+}
+
+// Backward iterates over the positions and values from the tail to the head.
+// The list can be modified during the iteration, including removing or moving the current element.
+// The positions are counted down from the size at the start of the iteration,
+// so they stay right as long as the elements not yet visited are not changed.
+func (l *List[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := l.size
+		l.walk(l.tail, false, func(e *Element[T]) bool {
+			i--
+			return yield(i, e.value)
+		})
+	}
+}
+
+// All iterates over the elements from the head to the tail.
+// The list can be modified during the iteration, including removing or moving the current element.
+func (l *List[T]) All() iter.Seq[*Element[T]] {
+	return l.From(l.head)
+}
+
+// From iterates over the elements from e to the tail.
+// Nothing is iterated if e is not an element of this list.
+func (l *List[T]) From(e *Element[T]) iter.Seq[*Element[T]] {
+	return func(yield func(*Element[T]) bool) {
+		if e != nil && e.list() == l {
+			l.walk(e, true, yield)
+		}
+	}
+}
+
+// BackwardFrom iterates over the elements from e to the head.
+// Nothing is iterated if e is not an element of this list.
+func (l *List[T]) BackwardFrom(e *Element[T]) iter.Seq[*Element[T]] {
+	return func(yield func(*Element[T]) bool) {
+		if e != nil && e.list() == l {
+			l.walk(e, false, yield)
+		}
+	}
+}
+
+// walk visits the elements starting at e.
+// The neighbour of each element is read before visiting it, like with container/list,
+// so that removing or moving the visited element does not change what is visited next.
+// Elements inserted next to the visited element are not visited.
+// If the neighbour was removed during the visit, the walk continues with the new neighbour of the visited element,
+// if it is still in the list.
+func (l *List[T]) walk(e *Element[T], forward bool, visit func(*Element[T]) bool) {
+	for e != nil {
+		next := e.step(forward)
+		if !visit(e) {
+			return
+		}
+		if next != nil && next.list() != l {
+			if e.list() != l {
 				return
 			}
-			temp = temp.next
+			next = e.step(forward)
 		}
+		e = next
 	}
 }
 
+func (e *Element[T]) step(forward bool) *Element[T] {
+	if forward {
+		return e.next
+	}
+	return e.prev
+}
+
 func (l *List[T]) Clone() *List[T] {
 	d := New[T]()
+	d.checked = l.checked
 	for v := range l.Values() {
 		d.Add(v)
 	}
 	return d
 }
+
+// verify panics if the list is inconsistent. It only runs with WithInvariantChecks.
+func (l *List[T]) verify() {
+	if !l.checked {
+		return
+	}
+
+	if (l.head == nil) != (l.tail == nil) {
+		panic(fmt.Sprintf("linkedlist: head %p and tail %p must be both set or both nil", l.head, l.tail))
+	}
+	if l.head != nil && l.head.prev != nil {
+		panic("linkedlist: head has a previous element")
+	}
+	if l.tail != nil && l.tail.next != nil {
+		panic("linkedlist: tail has a next element")
+	}
+
+	count := 0
+	var prev *Element[T]
+	for e := l.head; e != nil; e = e.next {
+		count++
+		if count > l.size {
+			panic(fmt.Sprintf("linkedlist: more elements than the size %d, or a cycle", l.size))
+		}
+		if e.prev != prev {
+			panic(fmt.Sprintf("linkedlist: element %d has a broken previous link", count-1))
+		}
+		if e.list() != l {
+			panic(fmt.Sprintf("linkedlist: element %d is not owned by the list", count-1))
+		}
+		prev = e
+	}
+	if prev != l.tail {
+		panic("linkedlist: the last element is not the tail")
+	}
+	if count != l.size {
+		panic(fmt.Sprintf("linkedlist: size is %d but there are %d elements", l.size, count))
+	}
+}
@@ -0,0 +1,141 @@
+package linkedlist
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"io"
+
+	"github.com/quintans/faults"
+)
+
+// Encoder writes lists as JSON arrays to a stream, one value at a time,
+// so that large lists are written without building the whole document in memory.
+type Encoder[T any] struct {
+	w io.Writer
+}
+
+func NewEncoder[T any](w io.Writer) *Encoder[T] {
+	return &Encoder[T]{w: w}
+}
+
+// Encode writes the list as a JSON array, keeping the order of the values
+func (e *Encoder[T]) Encode(l *List[T]) error {
+	w := bufio.NewWriter(e.w)
+	if err := writeJSON(w, l); err != nil {
+		return err
+	}
+	return faults.Wrap(w.Flush())
+}
+
+type byteWriter interface {
+	io.Writer
+	io.ByteWriter
+}
+
+func writeJSON[T any](w byteWriter, l *List[T]) error {
+	w.WriteByte('[')
+	for e := l.head; e != nil; e = e.next {
+		if e != l.head {
+			w.WriteByte(',')
+		}
+		b, err := json.Marshal(e.value)
+		if err != nil {
+			return faults.Wrap(err)
+		}
+		if _, err := w.Write(b); err != nil {
+			return faults.Wrap(err)
+		}
+	}
+	return faults.Wrap(w.WriteByte(']'))
+}
+
+// MarshalJSON implements the json.Marshaler interface, serializing the list as a JSON array.
+func (l *List[T]) MarshalJSON() ([]byte, error) {
+	var out bytes.Buffer
+	if err := writeJSON(&out, l); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, replacing the values of the list with the ones of the JSON array.
+func (l *List[T]) UnmarshalJSON(b []byte) error {
+	in := bytes.TrimSpace(b)
+	if bytes.Equal(in, []byte("null")) {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(in))
+	t, err := dec.Token()
+	if err != nil {
+		return faults.Wrap(err)
+	}
+	if delim, ok := t.(json.Delim); !ok || delim != '[' {
+		return faults.Errorf("expect JSON array open with '['")
+	}
+
+	d := New[T]()
+	for dec.More() {
+		var v T
+		if err := dec.Decode(&v); err != nil {
+			return faults.Wrap(err)
+		}
+		d.Add(v)
+	}
+
+	if _, err := dec.Token(); err != nil { // ']'
+		return faults.Wrap(err)
+	}
+
+	l.replace(d)
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface, serializing the list as a gob stream
+// with the number of values followed by the values.
+// It is also what the gob package uses to encode lists.
+func (l *List[T]) MarshalBinary() ([]byte, error) {
+	var out bytes.Buffer
+	enc := gob.NewEncoder(&out)
+	if err := enc.Encode(l.size); err != nil {
+		return nil, faults.Wrap(err)
+	}
+	for e := l.head; e != nil; e = e.next {
+		if err := enc.Encode(e.value); err != nil {
+			return nil, faults.Wrap(err)
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface, replacing the values of the list with the decoded ones.
+func (l *List[T]) UnmarshalBinary(b []byte) error {
+	dec := gob.NewDecoder(bytes.NewReader(b))
+	var size int
+	if err := dec.Decode(&size); err != nil {
+		return faults.Wrap(err)
+	}
+	if size < 0 {
+		return faults.Errorf("invalid list size: %d", size)
+	}
+
+	d := New[T]()
+	for range size {
+		var v T
+		if err := dec.Decode(&v); err != nil {
+			return faults.Wrap(err)
+		}
+		d.Add(v)
+	}
+
+	l.replace(d)
+	return nil
+}
+
+// replace replaces the elements of this list with the ones of the other list, leaving it empty
+func (l *List[T]) replace(other *List[T]) {
+	l.Clear()
+	l.SpliceAfter(nil, other)
+}
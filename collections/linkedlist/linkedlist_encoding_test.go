@@ -0,0 +1,108 @@
+package linkedlist_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quintans/ds/collections/linkedlist"
+)
+
+type item struct {
+	Name  string
+	Count int
+}
+
+func TestJSON(t *testing.T) {
+	l := linkedlist.FromSlice([]item{{"b", 2}, {"a", 0}, {"c", 3}})
+	b, err := json.Marshal(l)
+	require.NoError(t, err)
+	assert.Equal(t, `[{"Name":"b","Count":2},{"Name":"a","Count":0},{"Name":"c","Count":3}]`, string(b))
+
+	d := linkedlist.FromSlice([]item{{"old", 1}})
+	old := d.Head()
+	require.NoError(t, json.Unmarshal(b, d))
+	assert.Equal(t, collectValues(l), collectValues(d))
+	assert.Equal(t, 3, d.Size())
+	// the previous elements are no longer part of the list
+	old.Remove()
+	assert.Equal(t, 3, d.Size())
+
+	b, err = json.Marshal(linkedlist.New[int]())
+	require.NoError(t, err)
+	assert.Equal(t, `[]`, string(b))
+}
+
+func TestJSONField(t *testing.T) {
+	type doc struct {
+		Items *linkedlist.List[int] `json:"items"`
+	}
+	var d doc
+	require.NoError(t, json.Unmarshal([]byte(`{"items":[3,1,2]}`), &d))
+	assert.Equal(t, []int{3, 1, 2}, collectValues(d.Items))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"items":null}`), &d))
+
+	err := json.Unmarshal([]byte(`{"items":{"a":1}}`), &d)
+	assert.Error(t, err)
+	err = json.Unmarshal([]byte(`{"items":["a"]}`), &d)
+	assert.Error(t, err)
+}
+
+func TestEncoder(t *testing.T) {
+	l := linkedlist.FromSlice([]string{"x", `"quoted"`, "z"})
+	var out bytes.Buffer
+	require.NoError(t, linkedlist.NewEncoder[string](&out).Encode(l))
+	assert.Equal(t, `["x","\"quoted\"","z"]`, out.String())
+
+	err := linkedlist.NewEncoder[string](failingWriter{}).Encode(l)
+	assert.Error(t, err)
+
+	var invalid bytes.Buffer
+	bad := linkedlist.FromSlice([]any{1, func() {}})
+	assert.Error(t, linkedlist.NewEncoder[any](&invalid).Encode(bad))
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("failed")
+}
+
+func TestBinary(t *testing.T) {
+	l := linkedlist.FromSlice([]int{0, 5, -3, 0})
+	b, err := l.MarshalBinary()
+	require.NoError(t, err)
+
+	d := linkedlist.FromSlice([]int{9})
+	require.NoError(t, d.UnmarshalBinary(b))
+	assert.Equal(t, []int{0, 5, -3, 0}, collectValues(d))
+
+	empty, err := linkedlist.New[int]().MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, d.UnmarshalBinary(empty))
+	assert.Equal(t, 0, d.Size())
+
+	assert.Error(t, d.UnmarshalBinary(b[:len(b)-1]))
+}
+
+func TestGob(t *testing.T) {
+	type doc struct {
+		Title string
+		Items *linkedlist.List[item]
+	}
+	in := doc{Title: "t", Items: linkedlist.FromSlice([]item{{"a", 1}, {"b", 0}})}
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(in))
+
+	var out doc
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&out))
+	assert.Equal(t, "t", out.Title)
+	assert.Equal(t, collectValues(in.Items), collectValues(out.Items))
+}
@@ -0,0 +1,244 @@
+package linkedlist_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quintans/ds/collections/linkedlist"
+)
+
+func TestRemoveTwice(t *testing.T) {
+	l := linkedlist.New(linkedlist.WithInvariantChecks[int]())
+	e := l.Add(1)
+	l.Add(2)
+	e.Remove()
+	e.Remove()
+	assert.Equal(t, []int{2}, collectValues(l))
+	assert.Equal(t, 1, l.Size())
+}
+
+func TestForeignElement(t *testing.T) {
+	l1 := linkedlist.New(linkedlist.WithInvariantChecks[int]())
+	l1.Add(1)
+	l1.Add(2)
+	l2 := linkedlist.New(linkedlist.WithInvariantChecks[int]())
+	e3 := l2.Add(3)
+	l2.Add(4)
+
+	l1.MoveToFirst(e3)
+	l1.MoveToLast(e3)
+	assert.Equal(t, []int{1, 2}, collectValues(l1))
+	assert.Equal(t, []int{3, 4}, collectValues(l2))
+	assert.Equal(t, 2, l1.Size())
+	assert.Equal(t, 2, l2.Size())
+
+	// elements of a cleared list are detached
+	l2.Clear()
+	l1.MoveToFirst(e3)
+	e3.Remove()
+	assert.Equal(t, 2, l1.Size())
+	assert.Equal(t, 0, l2.Size())
+}
+
+// model mirrors two lists, a and b, as slices of their elements
+type model struct {
+	lists [2]*linkedlist.List[int]
+	elems [2][]*linkedlist.Element[int]
+	dead  []*linkedlist.Element[int]
+	next  int
+}
+
+// pick returns any element, live or removed, or nil when there are none
+func (m *model) pick(b byte) *linkedlist.Element[int] {
+	all := slices.Concat(m.elems[0], m.elems[1], m.dead)
+	if len(all) == 0 {
+		return nil
+	}
+	return all[int(b)%len(all)]
+}
+
+// owner returns the list index of the element and its position, or -1
+func (m *model) owner(e *linkedlist.Element[int]) (int, int) {
+	for li, elems := range m.elems {
+		if i := slices.Index(elems, e); i >= 0 {
+			return li, i
+		}
+	}
+	return -1, -1
+}
+
+func (m *model) value() int {
+	m.next++
+	return m.next
+}
+
+func (m *model) remove(li, i int) {
+	m.dead = append(m.dead, m.elems[li][i])
+	m.elems[li] = slices.Delete(m.elems[li], i, i+1)
+}
+
+func (m *model) apply(t *testing.T, op, x, y byte) {
+	// the operations target list a, and the other list is b
+	a, b := m.lists[0], m.lists[1]
+	switch op % 16 {
+	case 0:
+		m.elems[0] = append(m.elems[0], a.Add(m.value()))
+	case 1:
+		m.elems[0] = slices.Insert(m.elems[0], 0, a.AddFirst(m.value()))
+	case 2, 3:
+		mark := m.pick(x)
+		var e *linkedlist.Element[int]
+		if op%16 == 2 {
+			e = a.InsertAfter(mark, m.value())
+		} else {
+			e = a.InsertBefore(mark, m.value())
+		}
+		li, i := m.owner(mark)
+		if li != 0 {
+			require.Nil(t, e)
+			return
+		}
+		require.NotNil(t, e)
+		if op%16 == 2 {
+			i++
+		}
+		m.elems[0] = slices.Insert(m.elems[0], i, e)
+	case 4, 5:
+		e, mark := m.pick(x), m.pick(y)
+		if op%16 == 4 {
+			a.MoveAfter(e, mark)
+		} else {
+			a.MoveBefore(e, mark)
+		}
+		le, _ := m.owner(e)
+		lm, _ := m.owner(mark)
+		if le != 0 || lm != 0 || e == mark {
+			return
+		}
+		m.move(e, mark, op%16 == 4)
+	case 6:
+		e := m.pick(x)
+		a.MoveToFirst(e)
+		if li, _ := m.owner(e); li == 0 {
+			m.move(e, m.elems[0][0], false)
+		}
+	case 7:
+		e := m.pick(x)
+		a.MoveToLast(e)
+		if li, _ := m.owner(e); li == 0 {
+			m.move(e, m.elems[0][len(m.elems[0])-1], true)
+		}
+	case 8:
+		e := m.pick(x)
+		if e == nil {
+			return
+		}
+		e.Remove()
+		if li, i := m.owner(e); li >= 0 {
+			m.remove(li, i)
+		}
+	case 9:
+		v, err := a.RemoveFirst()
+		if len(m.elems[0]) == 0 {
+			require.Error(t, err)
+			return
+		}
+		require.NoError(t, err)
+		require.Equal(t, m.elems[0][0].Value(), v)
+		m.remove(0, 0)
+	case 10:
+		v, err := a.RemoveLast()
+		if len(m.elems[0]) == 0 {
+			require.Error(t, err)
+			return
+		}
+		require.NoError(t, err)
+		last := len(m.elems[0]) - 1
+		require.Equal(t, m.elems[0][last].Value(), v)
+		m.remove(0, last)
+	case 11:
+		m.elems[1] = append(m.elems[1], b.Add(m.value()))
+	case 12:
+		var mark *linkedlist.Element[int]
+		if y%4 != 0 {
+			mark = m.pick(x)
+		}
+		a.SpliceAfter(mark, b)
+		li, i := m.owner(mark)
+		if mark != nil && li != 0 {
+			return
+		}
+		// a nil mark splices at the head
+		m.elems[0] = slices.Insert(m.elems[0], i+1, m.elems[1]...)
+		m.elems[1] = nil
+	case 13:
+		m.dead = append(m.dead, m.elems[0]...)
+		m.elems[0] = nil
+		a.Clear()
+	case 14:
+		n := len(m.elems[1])
+		if x%2 == 0 {
+			a.PushBackList(b)
+			for e, i := a.Tail(), 0; i < n; e, i = e.Previous(), i+1 {
+				m.elems[0] = slices.Insert(m.elems[0], len(m.elems[0])-i, e)
+			}
+		} else {
+			a.PushFrontList(b)
+			for e, i := a.Head(), 0; i < n; e, i = e.Next(), i+1 {
+				m.elems[0] = slices.Insert(m.elems[0], i, e)
+			}
+		}
+	case 15:
+		m.lists[0], m.lists[1] = m.lists[1], m.lists[0]
+		m.elems[0], m.elems[1] = m.elems[1], m.elems[0]
+	}
+}
+
+// move moves e after or before mark in the model of list a
+func (m *model) move(e, mark *linkedlist.Element[int], after bool) {
+	if e == mark {
+		return
+	}
+	_, i := m.owner(e)
+	m.elems[0] = slices.Delete(m.elems[0], i, i+1)
+	_, j := m.owner(mark)
+	if after {
+		j++
+	}
+	m.elems[0] = slices.Insert(m.elems[0], j, e)
+}
+
+func (m *model) check(t *testing.T) {
+	for li, l := range m.lists {
+		require.Equal(t, len(m.elems[li]), l.Size())
+		var got []*linkedlist.Element[int]
+		for e := l.Head(); e != nil; e = e.Next() {
+			got = append(got, e)
+		}
+		if len(got) == 0 {
+			require.Empty(t, m.elems[li])
+			continue
+		}
+		require.Equal(t, m.elems[li], got)
+	}
+}
+
+func FuzzList(f *testing.F) {
+	f.Add([]byte{0, 0, 0, 1, 0, 0, 2, 0, 0, 3, 1, 0, 4, 0, 1, 5, 2, 0})
+	f.Add([]byte{0, 0, 0, 11, 0, 0, 11, 0, 0, 12, 0, 1, 8, 1, 0, 8, 1, 0, 13, 0, 0, 6, 0, 0})
+	f.Add([]byte{11, 0, 0, 0, 0, 0, 14, 0, 0, 14, 1, 0, 15, 0, 0, 7, 3, 0, 12, 2, 0, 9, 0, 0, 10, 0, 0})
+
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		m := &model{lists: [2]*linkedlist.List[int]{
+			linkedlist.New(linkedlist.WithInvariantChecks[int]()),
+			linkedlist.New(linkedlist.WithInvariantChecks[int]()),
+		}}
+		for i := 0; i+2 < len(ops); i += 3 {
+			m.apply(t, ops[i], ops[i+1], ops[i+2])
+			m.check(t)
+		}
+	})
+}
@@ -1,6 +1,10 @@
 package linkedlist_test
 
 import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -547,6 +551,475 @@ func TestClone(t *testing.T) {
 	assert.Nil(t, l4.Tail())
 }
 
+func TestInsertAfterBefore(t *testing.T) {
+	l := linkedlist.New[int]()
+	e2 := l.Add(2)
+	e4 := l.InsertAfter(e2, 4)
+	require.NotNil(t, e4)
+	l.InsertBefore(e4, 3)
+	l.InsertBefore(e2, 1)
+	l.InsertAfter(e4, 5)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, collectValues(l))
+	assert.Equal(t, 5, l.Size())
+	assert.Equal(t, 1, l.Head().Value())
+	assert.Equal(t, 5, l.Tail().Value())
+
+	other := linkedlist.New[int]()
+	foreign := other.Add(9)
+	assert.Nil(t, l.InsertAfter(foreign, 6))
+	assert.Nil(t, l.InsertBefore(foreign, 6))
+	assert.Nil(t, l.InsertAfter(nil, 6))
+	assert.Equal(t, 5, l.Size())
+	assert.Equal(t, 1, other.Size())
+}
+
+func TestMoveAfterBefore(t *testing.T) {
+	l := linkedlist.New[string]()
+	a := l.Add("a")
+	b := l.Add("b")
+	c := l.Add("c")
+
+	l.MoveAfter(a, c)
+	assert.Equal(t, []string{"b", "c", "a"}, collectValues(l))
+	assert.Equal(t, a, l.Tail())
+
+	l.MoveBefore(a, b)
+	assert.Equal(t, []string{"a", "b", "c"}, collectValues(l))
+	assert.Equal(t, a, l.Head())
+
+	l.MoveAfter(b, b)
+	l.MoveToLast(c)
+	l.MoveToFirst(a)
+	assert.Equal(t, []string{"a", "b", "c"}, collectValues(l))
+	assert.Equal(t, 3, l.Size())
+
+	other := linkedlist.New[string]()
+	x := other.Add("x")
+	l.MoveAfter(x, a)
+	l.MoveBefore(a, x)
+	assert.Equal(t, []string{"a", "b", "c"}, collectValues(l))
+	assert.Equal(t, []string{"x"}, collectValues(other))
+}
+
+func TestPushList(t *testing.T) {
+	l := linkedlist.New[int]()
+	l.Add(3)
+	l.Add(4)
+	other := linkedlist.New[int]()
+	other.Add(1)
+	other.Add(2)
+
+	l.PushFrontList(other)
+	l.PushBackList(other)
+	assert.Equal(t, []int{1, 2, 3, 4, 1, 2}, collectValues(l))
+	assert.Equal(t, []int{1, 2}, collectValues(other))
+
+	l.PushBackList(l)
+	assert.Equal(t, []int{1, 2, 3, 4, 1, 2, 1, 2, 3, 4, 1, 2}, collectValues(l))
+	other.PushFrontList(other)
+	assert.Equal(t, []int{1, 2, 1, 2}, collectValues(other))
+}
+
+func TestSpliceAfter(t *testing.T) {
+	l := linkedlist.New[int]()
+	e1 := l.Add(1)
+	l.Add(5)
+
+	other := linkedlist.New[int]()
+	e2 := other.Add(2)
+	other.Add(3)
+	e4 := other.Add(4)
+
+	l.SpliceAfter(e1, other)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, collectValues(l))
+	assert.Equal(t, 5, l.Size())
+	assert.Equal(t, 0, other.Size())
+	assert.Nil(t, other.Head())
+	assert.Nil(t, other.Tail())
+
+	// the spliced elements now belong to l
+	l.MoveToFirst(e4)
+	assert.Equal(t, []int{4, 1, 2, 3, 5}, collectValues(l))
+	e2.Remove()
+	assert.Equal(t, []int{4, 1, 3, 5}, collectValues(l))
+	assert.Equal(t, 4, l.Size())
+
+	// the emptied list is still usable and does not own the spliced elements
+	e6 := other.Add(6)
+	assert.Nil(t, other.InsertAfter(e4, 7))
+	other.Add(7)
+	l.SpliceAfter(nil, other)
+	assert.Equal(t, []int{6, 7, 4, 1, 3, 5}, collectValues(l))
+	assert.Equal(t, e6, l.Head())
+
+	// splicing to the tail, through several merged owners
+	third := linkedlist.New[int]()
+	third.Add(8)
+	l.SpliceAfter(l.Tail(), third)
+	assert.Equal(t, []int{6, 7, 4, 1, 3, 5, 8}, collectValues(l))
+	assert.Equal(t, 8, l.Tail().Value())
+	l.MoveToLast(e6)
+	assert.Equal(t, []int{7, 4, 1, 3, 5, 8, 6}, collectValues(l))
+
+	// a mark from another list, or the list itself, is ignored
+	l.SpliceAfter(nil, l)
+	foreign := linkedlist.New[int]()
+	f := foreign.Add(0)
+	third.Add(9)
+	l.SpliceAfter(f, third)
+	assert.Equal(t, 7, l.Size())
+	assert.Equal(t, 1, third.Size())
+}
+
+func TestSort(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for n := 1; n < 40; n++ {
+		l := linkedlist.New(linkedlist.WithInvariantChecks[int]())
+		values := make([]int, n)
+		for i := range values {
+			values[i] = r.IntN(10)
+			l.Add(values[i])
+		}
+		head := l.Head()
+
+		l.Sort(cmp.Compare[int])
+		slices.Sort(values)
+		assert.Equal(t, values, collectValues(l))
+		assert.True(t, l.IsSorted(cmp.Compare[int]))
+		assert.Equal(t, n, l.Size())
+		if head != nil {
+			// elements are relinked, not copied
+			head.Remove()
+			assert.Equal(t, n-1, l.Size())
+		}
+	}
+}
+
+type pair struct {
+	key   int
+	order int
+}
+
+func comparePairs(a, b pair) int {
+	return cmp.Compare(a.key, b.key)
+}
+
+func TestSortStable(t *testing.T) {
+	l := linkedlist.New[pair]()
+	for i, k := range []int{3, 1, 2, 1, 3, 2, 1} {
+		l.Add(pair{k, i})
+	}
+	l.Sort(comparePairs)
+	assert.Equal(t, []pair{{1, 1}, {1, 3}, {1, 6}, {2, 2}, {2, 5}, {3, 0}, {3, 4}}, collectValues(l))
+}
+
+func TestIsSorted(t *testing.T) {
+	l := linkedlist.New[int]()
+	assert.True(t, l.IsSorted(cmp.Compare[int]))
+	l.Add(1)
+	l.Add(1)
+	l.Add(2)
+	assert.True(t, l.IsSorted(cmp.Compare[int]))
+	l.Add(0)
+	assert.False(t, l.IsSorted(cmp.Compare[int]))
+}
+
+func TestInsertSorted(t *testing.T) {
+	l := linkedlist.New(linkedlist.WithInvariantChecks[pair]())
+	for i, k := range []int{5, 1, 3, 3, 7, 0, 5} {
+		e := l.InsertSorted(pair{k, i}, comparePairs)
+		assert.Equal(t, pair{k, i}, e.Value())
+	}
+	assert.Equal(t, []pair{{0, 5}, {1, 1}, {3, 2}, {3, 3}, {5, 0}, {5, 6}, {7, 4}}, collectValues(l))
+}
+
+func TestMergeSorted(t *testing.T) {
+	l := linkedlist.New(linkedlist.WithInvariantChecks[pair]())
+	other := linkedlist.New(linkedlist.WithInvariantChecks[pair]())
+	for i, k := range []int{1, 3, 3, 8} {
+		l.Add(pair{k, i})
+	}
+	var moved *linkedlist.Element[pair]
+	for i, k := range []int{0, 3, 4, 9, 10} {
+		moved = other.Add(pair{k, 10 + i})
+	}
+
+	l.MergeSorted(other, comparePairs)
+	assert.Equal(t, []pair{{0, 10}, {1, 0}, {3, 1}, {3, 2}, {3, 11}, {4, 12}, {8, 3}, {9, 13}, {10, 14}}, collectValues(l))
+	assert.Equal(t, 9, l.Size())
+	assert.Equal(t, 0, other.Size())
+	assert.Nil(t, other.Head())
+
+	// the merged elements now belong to l
+	l.MoveToFirst(moved)
+	assert.Equal(t, pair{10, 14}, l.Head().Value())
+
+	empty := linkedlist.New[pair]()
+	empty.MergeSorted(l, comparePairs)
+	assert.Equal(t, 9, empty.Size())
+	assert.Equal(t, 0, l.Size())
+}
+
+// reading spliced elements must not write to them, so that concurrent readers do not race
+func TestConcurrentReadsAfterSplice(t *testing.T) {
+	// each list is spliced at the head of a new one, nesting the owners of the original elements
+	l := linkedlist.FromSlice([]int{0, 1})
+	for i := range 10 {
+		next := linkedlist.FromSlice([]int{i + 2, i + 3})
+		next.SpliceAfter(nil, l)
+		l = next
+	}
+	// the original elements, with the deepest owners
+	mark := l.Tail().Previous()
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			count := 0
+			for range l.From(mark) {
+				count++
+			}
+			for range l.BackwardFrom(l.Tail()) {
+				count++
+			}
+			assert.Equal(t, 24, count)
+		})
+	}
+	wg.Wait()
+}
+
+func TestFromSeq(t *testing.T) {
+	l := linkedlist.FromSeq(slices.Values([]string{"a", "b"}))
+	assert.Equal(t, []string{"a", "b"}, collectValues(l))
+	assert.Equal(t, 2, l.Size())
+
+	l = linkedlist.FromSlice([]string{})
+	assert.Equal(t, 0, l.Size())
+	assert.Nil(t, l.Head())
+}
+
+func TestBackward(t *testing.T) {
+	l := linkedlist.FromSlice([]string{"a", "b", "c"})
+	var indexes []int
+	var values []string
+	for i, v := range l.Backward() {
+		indexes = append(indexes, i)
+		values = append(values, v)
+	}
+	assert.Equal(t, []int{2, 1, 0}, indexes)
+	assert.Equal(t, []string{"c", "b", "a"}, values)
+}
+
+func TestIterateFrom(t *testing.T) {
+	l := linkedlist.FromSlice([]int{1, 2, 3, 4})
+	mark := l.Head().Next()
+
+	var values []int
+	for e := range l.From(mark) {
+		values = append(values, e.Value())
+	}
+	assert.Equal(t, []int{2, 3, 4}, values)
+
+	values = nil
+	for e := range l.BackwardFrom(mark) {
+		values = append(values, e.Value())
+	}
+	assert.Equal(t, []int{2, 1}, values)
+
+	values = nil
+	for e := range l.All() {
+		values = append(values, e.Value())
+	}
+	assert.Equal(t, []int{1, 2, 3, 4}, values)
+
+	other := linkedlist.FromSlice([]int{9})
+	for range l.From(other.Head()) {
+		assert.Fail(t, "foreign elements are not iterated")
+	}
+}
+
+func TestIterateWhileMutating(t *testing.T) {
+	l := linkedlist.FromSlice([]int{1, 2, 3, 4, 5, 6}, linkedlist.WithInvariantChecks[int]())
+
+	// removing the current element
+	for e := range l.All() {
+		if e.Value()%2 == 0 {
+			e.Remove()
+		}
+	}
+	assert.Equal(t, []int{1, 3, 5}, collectValues(l))
+
+	// adding while iterating visits the new elements
+	var values []int
+	for v := range l.Values() {
+		values = append(values, v)
+		if v < 5 {
+			l.Add(v + 10)
+		}
+	}
+	assert.Equal(t, []int{1, 3, 5, 11, 13}, values)
+
+	// clearing stops the iteration
+	values = nil
+	for i, v := range l.Entries() {
+		values = append(values, v)
+		if i == 1 {
+			l.Clear()
+		}
+	}
+	assert.Equal(t, []int{1, 3}, values)
+
+	l = linkedlist.FromSlice([]int{1, 2, 3})
+	values = nil
+	for _, v := range l.Backward() {
+		values = append(values, v)
+		l.Head().Next().Remove()
+	}
+	assert.Equal(t, []int{3, 1}, values)
+
+	// moving the current element behind the iteration neither stops nor repeats it
+	l = linkedlist.FromSlice([]int{1, 2, 3}, linkedlist.WithInvariantChecks[int]())
+	values = nil
+	for e := range l.All() {
+		values = append(values, e.Value())
+		l.MoveToFirst(e)
+	}
+	assert.Equal(t, []int{1, 2, 3}, values)
+	assert.Equal(t, []int{3, 2, 1}, collectValues(l))
+
+	values = nil
+	for e := range l.BackwardFrom(l.Tail()) {
+		values = append(values, e.Value())
+		l.MoveToLast(e)
+	}
+	assert.Equal(t, []int{1, 2, 3}, values)
+	assert.Equal(t, []int{1, 2, 3}, collectValues(l))
+
+	// the positions are counted down without reading the size again
+	values = nil
+	var indexes []int
+	for i, v := range l.Backward() {
+		indexes = append(indexes, i)
+		values = append(values, v)
+		if v == 2 {
+			l.MoveToLast(l.Head().Next())
+		}
+	}
+	assert.Equal(t, []int{2, 1, 0}, indexes)
+	assert.Equal(t, []int{3, 2, 1}, values)
+}
+
+func isEven(v int) bool {
+	return v%2 == 0
+}
+
+func TestDeleteAll(t *testing.T) {
+	l := linkedlist.FromSlice([]int{2, 1, 4, 3, 6, 8}, linkedlist.WithInvariantChecks[int]())
+	assert.Equal(t, 4, l.DeleteAll(isEven))
+	assert.Equal(t, []int{1, 3}, collectValues(l))
+	assert.Equal(t, 0, l.DeleteAll(isEven))
+}
+
+func TestFindIndexOf(t *testing.T) {
+	l := linkedlist.FromSlice([]int{1, 3, 4, 6})
+	e := l.Find(isEven)
+	require.NotNil(t, e)
+	assert.Equal(t, 4, e.Value())
+	assert.Equal(t, 2, l.IndexOf(isEven))
+
+	l = linkedlist.FromSlice([]int{1, 3})
+	assert.Nil(t, l.Find(isEven))
+	assert.Equal(t, -1, l.IndexOf(isEven))
+}
+
+func TestReverse(t *testing.T) {
+	l := linkedlist.FromSlice([]int{1, 2, 3, 4}, linkedlist.WithInvariantChecks[int]())
+	head := l.Head()
+	l.Reverse()
+	assert.Equal(t, []int{4, 3, 2, 1}, collectValues(l))
+	assert.Equal(t, head, l.Tail())
+
+	l = linkedlist.New[int]()
+	l.Reverse()
+	assert.Equal(t, 0, l.Size())
+}
+
+func TestRotate(t *testing.T) {
+	tests := []struct {
+		n    int
+		want []int
+	}{
+		{0, []int{1, 2, 3, 4}},
+		{1, []int{4, 1, 2, 3}},
+		{3, []int{2, 3, 4, 1}},
+		{4, []int{1, 2, 3, 4}},
+		{6, []int{3, 4, 1, 2}},
+		{-1, []int{2, 3, 4, 1}},
+		{-5, []int{2, 3, 4, 1}},
+	}
+	for _, tt := range tests {
+		l := linkedlist.FromSlice([]int{1, 2, 3, 4}, linkedlist.WithInvariantChecks[int]())
+		l.Rotate(tt.n)
+		assert.Equal(t, tt.want, collectValues(l), "n=%d", tt.n)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	l := linkedlist.FromSlice([]int{1, 2, 3, 4}, linkedlist.WithInvariantChecks[int]())
+	removed := l.Tail()
+	l.Truncate(5)
+	assert.Equal(t, 4, l.Size())
+	l.Truncate(2)
+	assert.Equal(t, []int{1, 2}, collectValues(l))
+	assert.Nil(t, l.Tail().Next())
+	removed.Remove()
+	assert.Equal(t, 2, l.Size())
+	l.Truncate(-1)
+	assert.Equal(t, 0, l.Size())
+}
+
+func TestSubList(t *testing.T) {
+	l := linkedlist.FromSlice([]int{1, 2, 3, 4})
+	sub, err := l.SubList(1, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, collectValues(sub))
+	sub.Add(5)
+	assert.Equal(t, []int{1, 2, 3, 4}, collectValues(l))
+
+	sub, err = l.SubList(4, 4)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sub.Size())
+
+	_, err = l.SubList(-1, 2)
+	assert.Error(t, err)
+	_, err = l.SubList(2, 5)
+	assert.Error(t, err)
+	_, err = l.SubList(3, 2)
+	assert.Error(t, err)
+}
+
+func TestPartition(t *testing.T) {
+	l := linkedlist.FromSlice([]int{1, 2, 3, 4, 5, 6}, linkedlist.WithInvariantChecks[int]())
+	four := l.Find(func(v int) bool { return v == 4 })
+	even := l.Partition(isEven)
+	assert.Equal(t, []int{2, 4, 6}, collectValues(even))
+	assert.Equal(t, []int{1, 3, 5}, collectValues(l))
+
+	// the element was moved, not copied
+	even.MoveToFirst(four)
+	assert.Equal(t, []int{4, 2, 6}, collectValues(even))
+	l.MoveToFirst(four)
+	assert.Equal(t, []int{1, 3, 5}, collectValues(l))
+}
+
+func TestDedup(t *testing.T) {
+	eq := func(a, b int) bool { return a == b }
+	l := linkedlist.FromSlice([]int{1, 1, 2, 2, 2, 1, 3, 3}, linkedlist.WithInvariantChecks[int]())
+	assert.Equal(t, 4, l.Dedup(eq))
+	assert.Equal(t, []int{1, 2, 1, 3}, collectValues(l))
+	assert.Equal(t, 0, l.Dedup(eq))
+}
+
 // Helper function to collect values from the list for easier assertion
 func collectValues[T any](l *linkedlist.List[T]) []T {
 	var values []T
@@ -3,7 +3,6 @@ package linkedmap
 import (
 	"fmt"
 	"iter"
-	"maps"
 	"strings"
 
 	"github.com/quintans/ds/collections/linkedlist"
@@ -19,15 +18,34 @@ func WithCapacity[K comparable, V any](capacity int) Option[K, V] {
 	}
 }
 
+// WithAccessOrder makes the entries ordered by access, from the least to the most recently accessed,
+// instead of by insertion. Get and Put move the entry to the end.
+func WithAccessOrder[K comparable, V any]() Option[K, V] {
+	return func(l *Map[K, V]) {
+		l.accessOrder = true
+	}
+}
+
+// WithRemoveEldest sets a function called after each Put with the eldest entry, the first in the order.
+// If it returns true the entry is removed, which with WithAccessOrder makes a bounded LRU map.
+func WithRemoveEldest[K comparable, V any](removeEldest func(key K, value V) bool) Option[K, V] {
+	return func(l *Map[K, V]) {
+		l.removeEldest = removeEldest
+	}
+}
+
 type Map[K comparable, V any] struct {
 	keyOrder        *linkedlist.List[K]
-	entries         map[K]*entry[V]
+	entries         map[K]*entry[K, V]
 	initialCapacity int
+	accessOrder     bool
+	removeEldest    func(key K, value V) bool
 }
 
-type entry[V any] struct {
-	value           V
-	keyOrderRemover func()
+type entry[K, V any] struct {
+	value V
+	// element is the position of the key in the order
+	element *linkedlist.Element[K]
 }
 
 func New[K comparable, V any](options ...Option[K, V]) *Map[K, V] {
@@ -45,7 +63,7 @@ func New[K comparable, V any](options ...Option[K, V]) *Map[K, V] {
 
 func (m *Map[K, V]) Clear() {
 	m.keyOrder = linkedlist.New[K]()
-	m.entries = make(map[K]*entry[V], defaultCapacity)
+	m.entries = make(map[K]*entry[K, V], m.initialCapacity)
 }
 
 func (m *Map[K, V]) Size() int {
@@ -58,28 +76,138 @@ func (m *Map[K, V]) Get(key K) (V, bool) {
 		var zero V
 		return zero, false
 	}
+	if m.accessOrder {
+		m.keyOrder.MoveToLast(v.element)
+	}
 	return v.value, ok
 }
 
 func (m *Map[K, V]) Put(key K, value V) (V, bool) {
+	var move func(*linkedlist.Element[K])
+	if m.accessOrder {
+		move = m.keyOrder.MoveToLast
+	}
+	return m.put(key, value, m.keyOrder.Add, move)
+}
+
+// PutFirst puts the entry at the beginning of the order. If the key already exists, its value is replaced and it is moved to the beginning.
+func (m *Map[K, V]) PutFirst(key K, value V) (V, bool) {
+	return m.put(key, value, m.keyOrder.AddFirst, m.keyOrder.MoveToFirst)
+}
+
+// InsertBefore puts the entry right before the existing key. If the key already exists, its value is replaced and it is moved there.
+// It returns false, doing nothing, if the existing key is not in the map.
+func (m *Map[K, V]) InsertBefore(existingKey, key K, value V) bool {
+	mark, ok := m.entries[existingKey]
+	if !ok {
+		return false
+	}
+	m.put(key, value, func(k K) *linkedlist.Element[K] {
+		return m.keyOrder.InsertBefore(mark.element, k)
+	}, func(e *linkedlist.Element[K]) {
+		m.keyOrder.MoveBefore(e, mark.element)
+	})
+	return true
+}
+
+// InsertAfter puts the entry right after the existing key. If the key already exists, its value is replaced and it is moved there.
+// It returns false, doing nothing, if the existing key is not in the map.
+func (m *Map[K, V]) InsertAfter(existingKey, key K, value V) bool {
+	mark, ok := m.entries[existingKey]
+	if !ok {
+		return false
+	}
+	m.put(key, value, func(k K) *linkedlist.Element[K] {
+		return m.keyOrder.InsertAfter(mark.element, k)
+	}, func(e *linkedlist.Element[K]) {
+		m.keyOrder.MoveAfter(e, mark.element)
+	})
+	return true
+}
+
+// put sets the value of the key, adding new keys to the order with add and moving existing ones with move, if not nil
+func (m *Map[K, V]) put(key K, value V, add func(K) *linkedlist.Element[K], move func(*linkedlist.Element[K])) (V, bool) {
 	var old V
 	e, ok := m.entries[key]
 	if ok {
 		old = e.value
 		e.value = value
+		if move != nil {
+			move(e.element)
+		}
 	} else {
-		m.keyOrder.Add(key)
-		c := m.keyOrder.Tail()
-		e = &entry[V]{
-			value:           value,
-			keyOrderRemover: c.Remove,
+		e = &entry[K, V]{
+			value:   value,
+			element: add(key),
 		}
 		m.entries[key] = e
 	}
 
+	if m.removeEldest != nil {
+		eldest := m.keyOrder.Head().Value()
+		if m.removeEldest(eldest, m.entries[eldest].value) {
+			m.Delete(eldest)
+		}
+	}
+
 	return old, ok
 }
 
+// MoveToFront moves the key to the beginning of the order, returning false if it is not in the map
+func (m *Map[K, V]) MoveToFront(key K) bool {
+	e, ok := m.entries[key]
+	if ok {
+		m.keyOrder.MoveToFirst(e.element)
+	}
+	return ok
+}
+
+// MoveToBack moves the key to the end of the order, returning false if it is not in the map
+func (m *Map[K, V]) MoveToBack(key K) bool {
+	e, ok := m.entries[key]
+	if ok {
+		m.keyOrder.MoveToLast(e.element)
+	}
+	return ok
+}
+
+// First returns the first entry in the order
+func (m *Map[K, V]) First() (K, V, bool) {
+	return m.entry(m.keyOrder.Head())
+}
+
+// Last returns the last entry in the order
+func (m *Map[K, V]) Last() (K, V, bool) {
+	return m.entry(m.keyOrder.Tail())
+}
+
+// PopFirst removes and returns the first entry in the order
+func (m *Map[K, V]) PopFirst() (K, V, bool) {
+	return m.pop(m.keyOrder.Head())
+}
+
+// PopLast removes and returns the last entry in the order
+func (m *Map[K, V]) PopLast() (K, V, bool) {
+	return m.pop(m.keyOrder.Tail())
+}
+
+func (m *Map[K, V]) entry(e *linkedlist.Element[K]) (K, V, bool) {
+	if e == nil {
+		var k K
+		var v V
+		return k, v, false
+	}
+	return e.Value(), m.entries[e.Value()].value, true
+}
+
+func (m *Map[K, V]) pop(e *linkedlist.Element[K]) (K, V, bool) {
+	k, v, ok := m.entry(e)
+	if ok {
+		m.Delete(k)
+	}
+	return k, v, ok
+}
+
 func (m *Map[K, V]) ContainsKey(key K) bool {
 	_, ok := m.entries[key]
 	return ok
@@ -92,7 +220,7 @@ func (l *Map[K, V]) Delete(key K) (V, bool) {
 		return zero, false
 	}
 
-	old.keyOrderRemover()
+	old.element.Remove()
 	delete(l.entries, key)
 	return old.value, ok
 }
@@ -135,12 +263,71 @@ func (l *Map[K, V]) Values() iter.Seq[V] {
 	}
 }
 
+// ReverseEntries iterates over the entries from the last to the first in the order
+func (l *Map[K, V]) ReverseEntries() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, k := range l.keyOrder.Backward() {
+			v, ok := l.entries[k]
+			if !ok {
+				continue
+			}
+			if !yield(k, v.value) {
+				return
+			}
+		}
+	}
+}
+
+// ReverseKeys iterates over the keys from the last to the first in the order
+func (l *Map[K, V]) ReverseKeys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for _, k := range l.keyOrder.Backward() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// ReverseValues iterates over the values from the last to the first in the order
+func (l *Map[K, V]) ReverseValues() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, k := range l.keyOrder.Backward() {
+			v, ok := l.entries[k]
+			if !ok {
+				continue
+			}
+			if !yield(v.value) {
+				return
+			}
+		}
+	}
+}
+
+// Clone returns an independent copy of the map, with the same order and options.
+// The values are copied by assignment, see CloneFunc for deeper copies.
 func (l *Map[K, V]) Clone() *Map[K, V] {
-	return &Map[K, V]{
-		keyOrder:        l.keyOrder.Clone(),
-		entries:         maps.Clone(l.entries),
+	return l.CloneFunc(func(v V) V {
+		return v
+	})
+}
+
+// CloneFunc returns an independent copy of the map, with the same order and options, where the values are copied with fn.
+func (l *Map[K, V]) CloneFunc(fn func(V) V) *Map[K, V] {
+	c := &Map[K, V]{
+		keyOrder:        linkedlist.New[K](),
+		entries:         make(map[K]*entry[K, V], len(l.entries)),
 		initialCapacity: l.initialCapacity,
+		accessOrder:     l.accessOrder,
+		removeEldest:    l.removeEldest,
+	}
+	for k := range l.keyOrder.Values() {
+		c.entries[k] = &entry[K, V]{
+			value:   fn(l.entries[k].value),
+			element: c.keyOrder.Add(k),
+		}
 	}
+	return c
 }
 
 // String returns a string representation of container
@@ -158,7 +345,7 @@ func (m *Map[K, V]) String() string {
 			sb.WriteString(" ")
 		}
 		c++
-		sb.WriteString(fmt.Sprintf("%v:%v", k, v))
+		sb.WriteString(fmt.Sprintf("%v:%v", k, v.value))
 	}
 	sb.WriteString("]")
 	return sb.String()
@@ -2,154 +2,200 @@ package linkedmap
 
 import (
 	"bytes"
+	"encoding"
 	"encoding/json"
-	"errors"
-	"strings"
+	"reflect"
+	"strconv"
 
 	"github.com/quintans/faults"
 )
 
-var errEOA = errors.New("End of Array")
-
-type MapJSON Map[string, any]
-
-func NewJSON() *MapJSON {
-	return (*MapJSON)(New[string, any]())
-}
+var (
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
 
-func (m *MapJSON) Unwrap() *Map[string, any] {
-	return (*Map[string, any])(m)
-}
+// MarshalJSON implements the json.Marshaler interface, serializing the map as a JSON object with the keys in order.
+// Keys follow the rules of encoding/json: they must be strings, integers or implement encoding.TextMarshaler.
+func (m *Map[K, V]) MarshalJSON() ([]byte, error) {
+	if err := checkKeyType[K](); err != nil {
+		return nil, err
+	}
 
-// MarshalJSON implements the json.Marshaller interface, so it could be serialized.
-// When serializing, the keys of the map will keep the order they are added.
-func (mj *MapJSON) MarshalJSON() ([]byte, error) {
 	var out bytes.Buffer
-
-	m := mj.Unwrap()
-	out.WriteString("{")
+	out.WriteByte('{')
 	idx := 0
 	for k, v := range m.Entries() {
 		if idx > 0 {
-			out.WriteString(",")
+			out.WriteByte(',')
 		}
+		idx++
 
-		esc := strings.Replace(k, `"`, `\"`, -1)
-		out.WriteString(`"` + esc + `"`)
-
-		out.WriteString(":")
-
-		// marshal the value
-		b, err := json.Marshal(v)
+		key, err := marshalKey(k)
 		if err != nil {
-			return []byte{}, faults.Wrap(err)
+			return nil, err
+		}
+		b, err := json.Marshal(key)
+		if err != nil {
+			return nil, faults.Wrap(err)
 		}
-		out.WriteString(string(b))
+		out.Write(b)
+		out.WriteByte(':')
 
-		idx++
+		b, err = json.Marshal(v)
+		if err != nil {
+			return nil, faults.Wrap(err)
+		}
+		out.Write(b)
 	}
-
-	out.WriteString("}")
+	out.WriteByte('}')
 	return out.Bytes(), nil
 }
 
-func (m *MapJSON) UnmarshalJSON(b []byte) error {
-	// Using Decoder to parse the bytes.
+// UnmarshalJSON implements the json.Unmarshaler interface, adding the entries of the JSON object in the order they appear.
+// Values are decoded straight into V, and keys follow the rules of encoding/json.
+func (m *Map[K, V]) UnmarshalJSON(b []byte) error {
 	in := bytes.TrimSpace(b)
-	dec := json.NewDecoder(bytes.NewReader(in))
+	if bytes.Equal(in, []byte("null")) {
+		return nil
+	}
 
+	if err := checkKeyType[K](); err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(in))
 	t, err := dec.Token()
 	if err != nil {
 		return faults.Wrap(err)
 	}
-
-	// must open with a delim token '{'
 	if delim, ok := t.(json.Delim); !ok || delim != '{' {
 		return faults.Errorf("expect JSON object open with '{'")
 	}
 
-	err = m.parseObject(dec)
-	if err != nil {
-		return faults.Wrap(err)
-	}
-
-	t, err = dec.Token() //'}'
-	if err != nil {
-		return faults.Wrap(err)
-	}
-	if delim, ok := t.(json.Delim); !ok || delim != '}' {
-		return faults.Errorf("expect JSON object close with '}'")
+	// a zero map, as allocated by encoding/json, is not initialized
+	if m.entries == nil {
+		m.initialCapacity = defaultCapacity
+		m.Clear()
 	}
 
-	return nil
-}
-
-func (m *MapJSON) parseObject(dec *json.Decoder) error {
-	om := (*Map[string, any])(m)
-	for dec.More() { // Loop until it has no more tokens
+	for dec.More() {
 		t, err := dec.Token()
 		if err != nil {
 			return faults.Wrap(err)
 		}
-
-		key, ok := t.(string)
-		if !ok {
-			return faults.Errorf("key must be a string, got %T\n", t)
+		key, err := unmarshalKey[K](t.(string))
+		if err != nil {
+			return err
 		}
 
-		val, err := parseValue(dec)
-		if err != nil {
+		var v V
+		if err := dec.Decode(&v); err != nil {
 			return faults.Wrap(err)
 		}
-		om.Put(key, val)
+		m.Put(key, v)
+	}
+
+	if _, err := dec.Token(); err != nil { // '}'
+		return faults.Wrap(err)
 	}
 	return nil
 }
 
-func parseValue(dec *json.Decoder) (any, error) {
-	t, err := dec.Token()
-	if err != nil {
-		return nil, faults.Wrap(err)
-	}
-
-	switch tok := t.(type) {
-	case json.Delim:
-		switch tok {
-		case '[': // If it's an array
-			return parseArray(dec)
-		case '{': // If it's a map
-			om := (*MapJSON)(New[string, any]())
-			err := om.parseObject(dec)
-			if err != nil {
-				return nil, faults.Wrap(err)
-			}
-			_, err = dec.Token() // }
-			if err != nil {
-				return nil, faults.Wrap(err)
-			}
-			return om, nil
-		case ']':
-			return nil, faults.Wrap(errEOA)
-		case '}':
-			return nil, faults.New("unexpected '}'")
-		default:
-			return nil, faults.Errorf("Unexpected delimiter: %q", tok)
-		}
-	default:
-		return tok, nil
+// checkKeyType checks that the key type can be a JSON object key, like encoding/json does for map keys
+func checkKeyType[K any]() error {
+	kt := reflect.TypeFor[K]()
+	switch kt.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return nil
+	}
+	if kt.Implements(textMarshalerType) || reflect.PointerTo(kt).Implements(textUnmarshalerType) {
+		return nil
 	}
+	return faults.Errorf("unsupported map key type: %s", kt)
 }
 
-func parseArray(dec *json.Decoder) ([]any, error) {
-	ret := []any{}
-	for {
-		v, err := parseValue(dec)
-		if errors.Is(err, errEOA) {
-			return ret, nil
+// marshalKey converts the key to a JSON object key, like encoding/json does for map keys
+func marshalKey[K any](key K) (string, error) {
+	rv := reflect.ValueOf(&key).Elem()
+	if rv.Kind() == reflect.String {
+		return rv.String(), nil
+	}
+	if rv.Type().Implements(textMarshalerType) {
+		if rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return "", nil
 		}
+		b, err := rv.Interface().(encoding.TextMarshaler).MarshalText()
 		if err != nil {
-			return nil, faults.Wrap(err)
+			return "", faults.Wrap(err)
+		}
+		return string(b), nil
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	}
+	return "", faults.Errorf("unsupported map key type: %s", rv.Type())
+}
+
+// unmarshalKey converts a JSON object key to the key type, like encoding/json does for map keys
+func unmarshalKey[K any](s string) (K, error) {
+	var key K
+	rv := reflect.ValueOf(&key).Elem()
+	kt := rv.Type()
+	switch {
+	case reflect.PointerTo(kt).Implements(textUnmarshalerType):
+		if err := rv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return key, faults.Wrap(err)
+		}
+		return key, nil
+	case kt.Kind() == reflect.String:
+		rv.SetString(s)
+		return key, nil
+	}
+	switch kt.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || rv.OverflowInt(n) {
+			return key, faults.Errorf("invalid map key %q for type %s", s, kt)
+		}
+		rv.SetInt(n)
+		return key, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil || rv.OverflowUint(n) {
+			return key, faults.Errorf("invalid map key %q for type %s", s, kt)
 		}
-		ret = append(ret, v)
+		rv.SetUint(n)
+		return key, nil
 	}
+	return key, faults.Errorf("unsupported map key type: %s", kt)
+}
+
+type MapJSON Map[string, any]
+
+func NewJSON() *MapJSON {
+	return (*MapJSON)(New[string, any]())
+}
+
+func (m *MapJSON) Unwrap() *Map[string, any] {
+	return (*Map[string, any])(m)
+}
+
+// MarshalJSON implements the json.Marshaller interface, so it could be serialized.
+// When serializing, the keys of the map will keep the order they are added.
+func (mj *MapJSON) MarshalJSON() ([]byte, error) {
+	var out bytes.Buffer
+	if err := NewEncoder(nil).state(&out).encodeObject(mj); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func (m *MapJSON) UnmarshalJSON(b []byte) error {
+	return NewDecoder(bytes.NewReader(b)).Decode(m)
 }
@@ -0,0 +1,164 @@
+package linkedmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"iter"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/quintans/faults"
+)
+
+// WithCanonical makes the encoder write the RFC 8785 JSON Canonicalization Scheme (JCS),
+// for documents that are signed or hashed: no whitespace, keys sorted by their UTF-16 code units,
+// numbers formatted like JavaScript and strings with minimal escaping. It overrides the other options.
+// Since JCS numbers are IEEE 754 doubles, integers beyond 2^53 lose precision.
+func WithCanonical() EncoderOption {
+	return func(e *Encoder) {
+		e.canonical = true
+	}
+}
+
+// MarshalCanonical returns the RFC 8785 canonical JSON of the map, see WithCanonical
+func (m *MapJSON) MarshalCanonical() ([]byte, error) {
+	var out bytes.Buffer
+	if err := NewEncoder(nil, WithCanonical()).state(&out).encodeObject(m); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// MarshalCanonical returns the RFC 8785 canonical JSON of the map, see WithCanonical.
+// The map is encoded as with MarshalJSON and then canonicalized.
+func (m *Map[K, V]) MarshalCanonical() ([]byte, error) {
+	b, err := m.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	om := NewJSON()
+	if err := NewDecoder(bytes.NewReader(b), WithUseNumber()).Decode(om); err != nil {
+		return nil, err
+	}
+	return om.MarshalCanonical()
+}
+
+// canonicalEntries iterates over the entries sorted by the UTF-16 code units of the keys
+func canonicalEntries(m *MapJSON) iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		om := m.Unwrap()
+		keys := slices.Collect(om.Keys())
+		units := make(map[string][]uint16, len(keys))
+		for _, k := range keys {
+			units[k] = utf16.Encode([]rune(k))
+		}
+		slices.SortFunc(keys, func(a, b string) int {
+			return slices.Compare(units[a], units[b])
+		})
+		for _, k := range keys {
+			v, _ := om.Get(k)
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// encodeCanonical writes a value that is not an object or array.
+// Values of other types are encoded with encoding/json and then canonicalized.
+func (e *encodeState) encodeCanonical(v any) error {
+	switch t := v.(type) {
+	case nil:
+		e.w.WriteString("null")
+		return nil
+	case bool:
+		e.w.WriteString(strconv.FormatBool(t))
+		return nil
+	case string:
+		e.writeString(t)
+		return nil
+	}
+
+	if n, ok := asNumber(v); ok {
+		f, err := n.Float64()
+		if err != nil {
+			return faults.Errorf("number %s cannot be canonicalized: %w", n, err)
+		}
+		s, err := canonicalNumber(f)
+		if err != nil {
+			return err
+		}
+		e.w.WriteString(s)
+		return nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return faults.Wrap(err)
+	}
+	parsed, err := NewDecoder(bytes.NewReader(b), WithUseNumber()).parseValue(0)
+	if err != nil {
+		return err
+	}
+	// a value whose JSON is a number or string would otherwise come back here
+	switch parsed.(type) {
+	case *MapJSON, []any:
+		return e.encodeValue(parsed)
+	}
+	return e.encodeCanonical(parsed)
+}
+
+// canonicalNumber formats the number like ECMAScript's Number.prototype.toString, as RFC 8785 requires
+func canonicalNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", faults.Errorf("number %v is not valid JSON", f)
+	}
+	if f == 0 {
+		// also -0
+		return "0", nil
+	}
+
+	var sb strings.Builder
+	if f < 0 {
+		sb.WriteByte('-')
+		f = -f
+	}
+
+	// the shortest digits that round trip, like "1.2345e+06"
+	e := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exp, _ := strings.Cut(e, "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	k := len(digits)
+	x, _ := strconv.Atoi(exp)
+	// the decimal point is after the first n digits
+	n := x + 1
+
+	switch {
+	case k <= n && n <= 21:
+		sb.WriteString(digits)
+		sb.WriteString(strings.Repeat("0", n-k))
+	case 0 < n && n <= 21:
+		sb.WriteString(digits[:n])
+		sb.WriteByte('.')
+		sb.WriteString(digits[n:])
+	case -6 < n && n <= 0:
+		sb.WriteString("0.")
+		sb.WriteString(strings.Repeat("0", -n))
+		sb.WriteString(digits)
+	default:
+		sb.WriteString(digits[:1])
+		if k > 1 {
+			sb.WriteByte('.')
+			sb.WriteString(digits[1:])
+		}
+		sb.WriteByte('e')
+		if n-1 > 0 {
+			sb.WriteByte('+')
+		}
+		sb.WriteString(strconv.Itoa(n - 1))
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,140 @@
+package linkedmap_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quintans/ds/collections/linkedmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalRFC8785Example(t *testing.T) {
+	// RFC 8785, section 3.2.2
+	in := `{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`
+	want := `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`
+
+	om := linkedmap.NewJSON()
+	require.NoError(t, json.Unmarshal([]byte(in), om))
+	got, err := om.MarshalCanonical()
+	require.NoError(t, err)
+	require.Equal(t, want, string(got))
+
+	// the default mode keeps the insertion order
+	j, err := json.Marshal(om)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(j), `{"numbers":`))
+
+	// numbers decoded as json.Number give the same result
+	om = linkedmap.NewJSON()
+	require.NoError(t, linkedmap.NewDecoder(strings.NewReader(in), linkedmap.WithUseNumber()).Decode(om))
+	got, err = om.MarshalCanonical()
+	require.NoError(t, err)
+	require.Equal(t, want, string(got))
+}
+
+func TestCanonicalRFC8785Sorting(t *testing.T) {
+	// RFC 8785, section 3.2.3
+	in := `{
+  "\u20ac": "Euro Sign",
+  "\r": "Carriage Return",
+  "\ufb33": "Hebrew Letter Dalet With Dagesh",
+  "1": "One",
+  "\ud83d\ude00": "Emoji: Grinning Face",
+  "\u0080": "Control",
+  "\u00f6": "Latin Small Letter O With Diaeresis"
+}`
+	om := linkedmap.NewJSON()
+	require.NoError(t, json.Unmarshal([]byte(in), om))
+
+	var out bytes.Buffer
+	require.NoError(t, linkedmap.NewEncoder(&out, linkedmap.WithCanonical(), linkedmap.WithIndent("", "  ")).Encode(om))
+
+	want := "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"ö\":\"Latin Small Letter O With Diaeresis\"," +
+		"\"€\":\"Euro Sign\",\"😀\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}\n"
+	require.Equal(t, want, out.String())
+}
+
+func TestCanonicalRFC8785Numbers(t *testing.T) {
+	// RFC 8785, appendix B
+	tests := []struct {
+		bits uint64
+		want string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	}
+	for _, tt := range tests {
+		om := linkedmap.NewJSON()
+		om.Unwrap().Put("n", math.Float64frombits(tt.bits))
+		got, err := om.MarshalCanonical()
+		require.NoError(t, err)
+		require.Equal(t, `{"n":`+tt.want+`}`, string(got), "%016x", tt.bits)
+	}
+
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		om := linkedmap.NewJSON()
+		om.Unwrap().Put("n", f)
+		_, err := om.MarshalCanonical()
+		require.Error(t, err)
+	}
+}
+
+func TestCanonicalOtherValues(t *testing.T) {
+	om := linkedmap.NewJSON()
+	om.Unwrap().Put("time", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	om.Unwrap().Put("column", Column{Type: "int", Nullable: true})
+	om.Unwrap().Put("ints", []int{1, 100, -7})
+	om.Unwrap().Put("html", "<a&b>\u2028")
+	om.Unwrap().Put("big", int64(1)<<60)
+
+	got, err := om.MarshalCanonical()
+	require.NoError(t, err)
+	require.Equal(t, "{\"big\":1152921504606847000,\"column\":{\"nullable\":true,\"type\":\"int\"},\"html\":\"<a&b>\u2028\",\"ints\":[1,100,-7],\"time\":\"2024-01-02T03:04:05Z\"}", string(got))
+}
+
+func TestTypedMapCanonical(t *testing.T) {
+	m := linkedmap.New[string, Column]()
+	m.Put("name", Column{Type: "text"})
+	m.Put("id", Column{Type: "int", Nullable: true})
+
+	got, err := m.MarshalCanonical()
+	require.NoError(t, err)
+	require.Equal(t, `{"id":{"nullable":true,"type":"int"},"name":{"type":"text"}}`, string(got))
+
+	n := linkedmap.New[int, float64]()
+	n.Put(10, 1e21)
+	n.Put(9, 0.1)
+	got, err = n.MarshalCanonical()
+	require.NoError(t, err)
+	require.Equal(t, `{"10":1e+21,"9":0.1}`, string(got))
+}
@@ -0,0 +1,407 @@
+package linkedmap
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/quintans/faults"
+)
+
+// ApplyMergePatch applies the RFC 7396 merge patch to the target, returning it.
+// Keys with null values in the patch are removed, existing keys keep their position and new keys are appended in the order of the patch.
+// A nil target is an empty object and a nil patch, the JSON null, returns nil.
+// The values of the patch are copied, so the target does not share them.
+func ApplyMergePatch(target, patch *MapJSON) *MapJSON {
+	if patch == nil {
+		return nil
+	}
+	if target == nil {
+		target = NewJSON()
+	}
+
+	t := target.Unwrap()
+	for k, v := range patch.Unwrap().Entries() {
+		if v == nil {
+			t.Delete(k)
+			continue
+		}
+		if p, ok := v.(*MapJSON); ok {
+			current, _ := t.Get(k)
+			obj, _ := current.(*MapJSON)
+			t.Put(k, ApplyMergePatch(obj, p))
+			continue
+		}
+		t.Put(k, cloneValue(v))
+	}
+	return target
+}
+
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+// Operation is a JSON Patch operation, as defined by RFC 6902.
+// From is only used by move and copy, and Value by add, replace and test.
+type Operation struct {
+	Op    string
+	Path  string
+	From  string
+	Value any
+}
+
+// MarshalJSON implements the json.Marshaler interface, writing only the members used by the operation
+func (o Operation) MarshalJSON() ([]byte, error) {
+	m := NewJSON()
+	om := m.Unwrap()
+	om.Put("op", o.Op)
+	om.Put("path", o.Path)
+	switch o.Op {
+	case OpMove, OpCopy:
+		om.Put("from", o.From)
+	case OpAdd, OpReplace, OpTest:
+		om.Put("value", o.Value)
+	}
+	return m.MarshalJSON()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Object values are decoded as *MapJSON, keeping their order.
+func (o *Operation) UnmarshalJSON(b []byte) error {
+	m := NewJSON()
+	if err := m.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	om := m.Unwrap()
+
+	member := func(name string) (string, error) {
+		v, ok := om.Get(name)
+		if !ok {
+			return "", faults.Errorf("patch operation is missing %q", name)
+		}
+		s, ok := v.(string)
+		if !ok {
+			return "", faults.Errorf("patch operation %q must be a string, got %T", name, v)
+		}
+		return s, nil
+	}
+
+	op, err := member("op")
+	if err != nil {
+		return err
+	}
+	path, err := member("path")
+	if err != nil {
+		return err
+	}
+	*o = Operation{Op: op, Path: path}
+
+	switch op {
+	case OpMove, OpCopy:
+		if o.From, err = member("from"); err != nil {
+			return err
+		}
+	case OpAdd, OpReplace, OpTest:
+		v, ok := om.Get("value")
+		if !ok {
+			return faults.Errorf("patch operation is missing %q", "value")
+		}
+		o.Value = v
+	}
+	return nil
+}
+
+// Patch is a JSON Patch document, as defined by RFC 6902
+type Patch []Operation
+
+// Apply applies the operations in order to the document.
+// The patch is applied to a copy that replaces the contents of the document only if all operations succeed,
+// so on error the document is unchanged, and on success nested values previously read from it are no longer part of it.
+// Keys added to objects are appended, existing keys keep their position,
+// and a key moved to a new name in the same object takes the position of the old one.
+func (p Patch) Apply(doc *MapJSON) error {
+	work := cloneObject(doc)
+	for i, op := range p {
+		if err := work.apply(op); err != nil {
+			return faults.Errorf("patch operation %d, %s %q: %w", i, op.Op, op.Path, err)
+		}
+	}
+	*doc = *work
+	return nil
+}
+
+func (m *MapJSON) apply(op Operation) error {
+	switch op.Op {
+	case OpAdd:
+		return m.add(op.Path, cloneValue(op.Value))
+	case OpRemove:
+		_, err := m.DeletePointer(op.Path)
+		return err
+	case OpReplace:
+		if op.Path == "" {
+			return m.replaceRoot(cloneValue(op.Value))
+		}
+		if _, err := m.GetPointer(op.Path); err != nil {
+			return err
+		}
+		return m.SetPointer(op.Path, cloneValue(op.Value))
+	case OpMove:
+		return m.move(op.From, op.Path)
+	case OpCopy:
+		v, err := m.GetPointer(op.From)
+		if err != nil {
+			return err
+		}
+		return m.add(op.Path, cloneValue(v))
+	case OpTest:
+		v, err := m.GetPointer(op.Path)
+		if err != nil {
+			return err
+		}
+		if !equalValues(v, op.Value) {
+			return faults.New("test failed")
+		}
+		return nil
+	default:
+		return faults.Errorf("unknown patch operation %q", op.Op)
+	}
+}
+
+// add sets the value of an object key, or inserts it in an array, whose parent must exist
+func (m *MapJSON) add(path string, value any) error {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return m.replaceRoot(value)
+	}
+
+	parentPointer := pointerTo(tokens[:len(tokens)-1])
+	parent, err := m.GetPointer(parentPointer)
+	if err != nil {
+		return err
+	}
+
+	key := tokens[len(tokens)-1]
+	switch p := parent.(type) {
+	case *MapJSON:
+		p.Unwrap().Put(key, value)
+		return nil
+	case []any:
+		idx, err := arrayIndex(key, len(p), true)
+		if err != nil {
+			return err
+		}
+		return m.SetPointer(parentPointer, slices.Insert(p, idx, value))
+	default:
+		return faults.Errorf("%s: %T is not an object or array", parentPointer, parent)
+	}
+}
+
+func (m *MapJSON) replaceRoot(value any) error {
+	obj, ok := value.(*MapJSON)
+	if !ok || obj == nil {
+		return faults.Errorf("the root of the document must be an object, got %T", value)
+	}
+	*m = *obj
+	return nil
+}
+
+func (m *MapJSON) move(from, path string) error {
+	if from == path {
+		return nil
+	}
+	if strings.HasPrefix(path, from+"/") {
+		return faults.Errorf("cannot move %q into its own child %q", from, path)
+	}
+
+	v, err := m.GetPointer(from)
+	if err != nil {
+		return err
+	}
+
+	// renaming a key of an object keeps its position
+	fromTokens, _ := parsePointer(from)
+	toTokens, err := parsePointer(path)
+	if err != nil {
+		return err
+	}
+	if len(fromTokens) > 0 && len(toTokens) > 0 {
+		fromParent := pointerTo(fromTokens[:len(fromTokens)-1])
+		if fromParent == pointerTo(toTokens[:len(toTokens)-1]) {
+			parent, _ := m.GetPointer(fromParent)
+			fromKey, toKey := fromTokens[len(fromTokens)-1], toTokens[len(toTokens)-1]
+			if p, ok := parent.(*MapJSON); ok && !p.Unwrap().ContainsKey(toKey) {
+				p.Unwrap().InsertBefore(fromKey, toKey, v)
+				p.Unwrap().Delete(fromKey)
+				return nil
+			}
+		}
+	}
+
+	if _, err := m.DeletePointer(from); err != nil {
+		return err
+	}
+	return m.add(path, v)
+}
+
+// Diff returns a patch that changes a into b.
+// Unchanged values produce no operations, objects are compared key by key,
+// and arrays are only changed between their common prefix and suffix.
+// Key order is not compared: keys in both keep their position in a, and new keys are added in the order of b.
+func Diff(a, b *MapJSON) Patch {
+	var p Patch
+	p.diffObjects("", a, b)
+	return p
+}
+
+func (p *Patch) diffObjects(path string, a, b *MapJSON) {
+	for k := range a.Unwrap().Keys() {
+		if !b.Unwrap().ContainsKey(k) {
+			*p = append(*p, Operation{Op: OpRemove, Path: path + "/" + escapeToken(k)})
+		}
+	}
+	for k, bv := range b.Unwrap().Entries() {
+		childPath := path + "/" + escapeToken(k)
+		if av, ok := a.Unwrap().Get(k); ok {
+			p.diffValues(childPath, av, bv)
+		} else {
+			*p = append(*p, Operation{Op: OpAdd, Path: childPath, Value: cloneValue(bv)})
+		}
+	}
+}
+
+func (p *Patch) diffArrays(path string, a, b []any) {
+	prefix := 0
+	for prefix < min(len(a), len(b)) && equalValues(a[prefix], b[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < min(len(a), len(b))-prefix && equalValues(a[len(a)-1-suffix], b[len(b)-1-suffix]) {
+		suffix++
+	}
+
+	am, bm := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	common := min(len(am), len(bm))
+	for i := range common {
+		p.diffValues(path+"/"+strconv.Itoa(prefix+i), am[i], bm[i])
+	}
+	for i := common; i < len(bm); i++ {
+		*p = append(*p, Operation{Op: OpAdd, Path: path + "/" + strconv.Itoa(prefix+i), Value: cloneValue(bm[i])})
+	}
+	// removing at the same index shifts the next elements there
+	for range len(am) - common {
+		*p = append(*p, Operation{Op: OpRemove, Path: path + "/" + strconv.Itoa(prefix+common)})
+	}
+}
+
+func (p *Patch) diffValues(path string, a, b any) {
+	if equalValues(a, b) {
+		return
+	}
+	switch x := a.(type) {
+	case *MapJSON:
+		if y, ok := b.(*MapJSON); ok && x != nil && y != nil {
+			p.diffObjects(path, x, y)
+			return
+		}
+	case []any:
+		if y, ok := b.([]any); ok {
+			p.diffArrays(path, x, y)
+			return
+		}
+	}
+	*p = append(*p, Operation{Op: OpReplace, Path: path, Value: cloneValue(b)})
+}
+
+// cloneObject returns a deep copy of the object
+func cloneObject(m *MapJSON) *MapJSON {
+	return (*MapJSON)(m.Unwrap().CloneFunc(cloneValue))
+}
+
+// cloneValue returns a deep copy of objects and arrays, and the value itself otherwise
+func cloneValue(v any) any {
+	switch t := v.(type) {
+	case *MapJSON:
+		if t == nil {
+			return t
+		}
+		return cloneObject(t)
+	case []any:
+		if t == nil {
+			return t
+		}
+		c := make([]any, len(t))
+		for i, e := range t {
+			c[i] = cloneValue(e)
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// equalValues compares JSON values, where objects are equal regardless of the order of the keys
+// and numbers are equal regardless of their Go type
+func equalValues(a, b any) bool {
+	switch x := a.(type) {
+	case *MapJSON:
+		y, ok := b.(*MapJSON)
+		if !ok || x == nil || y == nil {
+			return ok && x == y
+		}
+		if x.Unwrap().Size() != y.Unwrap().Size() {
+			return false
+		}
+		for k, xv := range x.Unwrap().Entries() {
+			yv, ok := y.Unwrap().Get(k)
+			if !ok || !equalValues(xv, yv) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		y, ok := b.([]any)
+		return ok && slices.EqualFunc(x, y, equalValues)
+	}
+
+	if x, ok := asNumber(a); ok {
+		y, ok := asNumber(b)
+		return ok && equalNumbers(x, y)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func asNumber(v any) (json.Number, bool) {
+	if n, ok := v.(json.Number); ok {
+		return n, true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return json.Number(strconv.FormatInt(rv.Int(), 10)), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return json.Number(strconv.FormatUint(rv.Uint(), 10)), true
+	case reflect.Float32, reflect.Float64:
+		return json.Number(strconv.FormatFloat(rv.Float(), 'g', -1, 64)), true
+	}
+	return "", false
+}
+
+func equalNumbers(a, b json.Number) bool {
+	if x, err := a.Int64(); err == nil {
+		if y, err := b.Int64(); err == nil {
+			return x == y
+		}
+	}
+	x, errA := a.Float64()
+	y, errB := b.Float64()
+	return errA == nil && errB == nil && x == y
+}
@@ -0,0 +1,226 @@
+package linkedmap_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/quintans/ds/collections/linkedmap"
+	"github.com/stretchr/testify/require"
+)
+
+func parseJSON(t *testing.T, s string) *linkedmap.MapJSON {
+	t.Helper()
+	om := linkedmap.NewJSON()
+	require.NoError(t, json.Unmarshal([]byte(s), om))
+	return om
+}
+
+func toJSON(t *testing.T, om *linkedmap.MapJSON) string {
+	t.Helper()
+	b, err := json.Marshal(om)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		target, patch, want string
+	}{
+		// RFC 7396, Appendix A, for object targets and patches
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		// order is kept
+		{`{"x":1,"y":2,"z":3}`, `{"w":0,"z":30,"x":10}`, `{"x":10,"y":2,"z":30,"w":0}`},
+		{`{"a":1,"b":{"c":1,"d":2}}`, `{"b":{"e":3,"c":null}}`, `{"a":1,"b":{"d":2,"e":3}}`},
+	}
+	for _, tt := range tests {
+		got := linkedmap.ApplyMergePatch(parseJSON(t, tt.target), parseJSON(t, tt.patch))
+		require.Equal(t, tt.want, toJSON(t, got), "%s + %s", tt.target, tt.patch)
+	}
+
+	require.Equal(t, `{"a":{"b":1}}`, toJSON(t, linkedmap.ApplyMergePatch(nil, parseJSON(t, `{"a":{"b":1,"c":null}}`))))
+	require.Nil(t, linkedmap.ApplyMergePatch(parseJSON(t, `{"a":1}`), nil))
+
+	// the target does not share values with the patch
+	patch := parseJSON(t, `{"a":[1,{"b":2}]}`)
+	target := linkedmap.ApplyMergePatch(linkedmap.NewJSON(), patch)
+	require.NoError(t, patch.SetPointer("/a/1/b", 3))
+	require.Equal(t, `{"a":[1,{"b":2}]}`, toJSON(t, target))
+}
+
+func TestPatchApply(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		// RFC 6902, Appendix A
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"foo":"bar","baz":"qux"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`, `{"foo":"bar","baz":"qux"}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`},
+		// order is kept
+		{`{"a":1,"b":2,"c":3}`, `[{"op":"move","from":"/b","path":"/x"}]`, `{"a":1,"x":2,"c":3}`},
+		{`{"a":1,"b":2,"c":3}`, `[{"op":"move","from":"/a","path":"/c"}]`, `{"b":2,"c":1}`},
+		{`{"a":{"k":1},"b":{}}`, `[{"op":"move","from":"/a/k","path":"/b/k"}]`, `{"a":{},"b":{"k":1}}`},
+		{`{"a":1,"b":2}`, `[{"op":"add","path":"/a","value":3},{"op":"copy","from":"/a","path":"/c"}]`, `{"a":3,"b":2,"c":3}`},
+		{`{"a":1}`, `[{"op":"replace","path":"","value":{"z":0}}]`, `{"z":0}`},
+		{`{"a":{"b":1},"c":2}`, `[{"op":"move","from":"/a","path":""}]`, `{"b":1}`},
+	}
+	for _, tt := range tests {
+		var p linkedmap.Patch
+		require.NoError(t, json.Unmarshal([]byte(tt.patch), &p))
+		doc := parseJSON(t, tt.doc)
+		require.NoError(t, p.Apply(doc), tt.patch)
+		require.Equal(t, tt.want, toJSON(t, doc), tt.patch)
+	}
+}
+
+func TestPatchApplyErrors(t *testing.T) {
+	tests := []struct {
+		doc, patch string
+	}{
+		// RFC 6902, Appendix A
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`},
+		{`{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`},
+		{`{"foo":{"bar":"baz"}}`, `[{"op":"test","path":"/foo","value":{"bar":"baz","x":1}}]`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/2","value":1}]`},
+		{`{"a":1}`, `[{"op":"replace","path":"/b","value":1}]`},
+		{`{"a":1}`, `[{"op":"remove","path":"/b"}]`},
+		{`{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a/c"}]`},
+		{`{"a":1}`, `[{"op":"copy","from":"/b","path":"/c"}]`},
+		{`{"a":1}`, `[{"op":"replace","path":"","value":1}]`},
+		{`{"a":1}`, `[{"op":"unknown","path":"/a"}]`},
+		// the first operation is undone
+		{`{"a":1}`, `[{"op":"add","path":"/b","value":1},{"op":"remove","path":"/c"}]`},
+	}
+	for _, tt := range tests {
+		var p linkedmap.Patch
+		require.NoError(t, json.Unmarshal([]byte(tt.patch), &p))
+		doc := parseJSON(t, tt.doc)
+		require.Error(t, p.Apply(doc), tt.patch)
+		require.Equal(t, tt.doc, toJSON(t, doc), tt.patch)
+	}
+
+	for _, s := range []string{`[{"path":"/a"}]`, `[{"op":"add","path":"/a"}]`, `[{"op":"move","path":"/a"}]`, `[{"op":1,"path":"/a"}]`} {
+		var p linkedmap.Patch
+		require.Error(t, json.Unmarshal([]byte(s), &p), s)
+	}
+}
+
+func TestPatchTestComparesJSONValues(t *testing.T) {
+	doc := parseJSON(t, `{"o":{"x":1,"y":[1,2]},"n":10,"z":null}`)
+	p := linkedmap.Patch{
+		{Op: linkedmap.OpTest, Path: "/o", Value: parseJSON(t, `{"y":[1.0,2],"x":1}`)},
+		{Op: linkedmap.OpTest, Path: "/n", Value: 10},
+		{Op: linkedmap.OpTest, Path: "/n", Value: json.Number("1e1")},
+		{Op: linkedmap.OpTest, Path: "/z", Value: nil},
+	}
+	require.NoError(t, p.Apply(doc))
+}
+
+func TestPatchMarshal(t *testing.T) {
+	p := linkedmap.Patch{
+		{Op: linkedmap.OpAdd, Path: "/a", Value: nil},
+		{Op: linkedmap.OpRemove, Path: "/b"},
+		{Op: linkedmap.OpMove, From: "/c", Path: "/d"},
+		{Op: linkedmap.OpReplace, Path: "/e", Value: parseJSON(t, `{"z":1,"y":2}`)},
+	}
+	b, err := json.Marshal(p)
+	require.NoError(t, err)
+	require.Equal(t, `[{"op":"add","path":"/a","value":null},{"op":"remove","path":"/b"},{"op":"move","path":"/d","from":"/c"},{"op":"replace","path":"/e","value":{"z":1,"y":2}}]`, string(b))
+
+	var back linkedmap.Patch
+	require.NoError(t, json.Unmarshal(b, &back))
+	b2, err := json.Marshal(back)
+	require.NoError(t, err)
+	require.Equal(t, string(b), string(b2))
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		a, b, patch string
+	}{
+		{`{"a":1}`, `{"a":1}`, `null`},
+		{`{"a":1,"b":2}`, `{"a":1,"b":3,"c":4}`, `[{"op":"replace","path":"/b","value":3},{"op":"add","path":"/c","value":4}]`},
+		{`{"a":{"x":1,"y":2}}`, `{"a":{"x":1}}`, `[{"op":"remove","path":"/a/y"}]`},
+		{`{"a/b":[1,2,3,4]}`, `{"a/b":[1,9,3,4]}`, `[{"op":"replace","path":"/a~1b/1","value":9}]`},
+		{`{"l":[1,2,3]}`, `{"l":[1,5,6,2,3]}`, `[{"op":"add","path":"/l/1","value":5},{"op":"add","path":"/l/2","value":6}]`},
+		{`{"l":[1,5,6,2,3]}`, `{"l":[1,3]}`, `[{"op":"remove","path":"/l/1"},{"op":"remove","path":"/l/1"},{"op":"remove","path":"/l/1"}]`},
+		{`{"a":[1]}`, `{"a":{"b":1}}`, `[{"op":"replace","path":"/a","value":{"b":1}}]`},
+		// order alone is not a difference
+		{`{"a":1,"b":2}`, `{"b":2,"a":1}`, `null`},
+	}
+	for _, tt := range tests {
+		a, b := parseJSON(t, tt.a), parseJSON(t, tt.b)
+		p := linkedmap.Diff(a, b)
+		got, err := json.Marshal(p)
+		require.NoError(t, err)
+		require.Equal(t, tt.patch, string(got), "%s -> %s", tt.a, tt.b)
+
+		require.NoError(t, p.Apply(a))
+		requireSameJSON(t, tt.b, toJSON(t, a))
+	}
+}
+
+// requireSameJSON compares the documents ignoring the order of the keys
+func requireSameJSON(t *testing.T, want, got string) {
+	t.Helper()
+	var w, g any
+	require.NoError(t, json.Unmarshal([]byte(want), &w))
+	require.NoError(t, json.Unmarshal([]byte(got), &g))
+	require.Equal(t, w, g)
+}
+
+func randomJSON(r *rand.Rand, depth int) any {
+	switch n := r.IntN(6); {
+	case depth > 2 || n < 3:
+		return []any{nil, true, float64(r.IntN(4)), fmt.Sprint("s", r.IntN(3))}[r.IntN(4)]
+	case n < 5:
+		return randomObject(r, depth+1)
+	default:
+		a := []any{}
+		for range r.IntN(4) {
+			a = append(a, randomJSON(r, depth+1))
+		}
+		return a
+	}
+}
+
+func randomObject(r *rand.Rand, depth int) *linkedmap.MapJSON {
+	om := linkedmap.NewJSON()
+	for range r.IntN(5) {
+		om.Unwrap().Put(fmt.Sprint("k", r.IntN(6)), randomJSON(r, depth))
+	}
+	return om
+}
+
+func TestDiffProperty(t *testing.T) {
+	r := rand.New(rand.NewPCG(7, 7))
+	for range 500 {
+		a, b := randomObject(r, 0), randomObject(r, 0)
+		want := toJSON(t, b)
+
+		p := linkedmap.Diff(a, b)
+		require.Equal(t, want, toJSON(t, b), "diff must not change b")
+		require.NoError(t, p.Apply(a))
+		requireSameJSON(t, want, toJSON(t, a))
+		require.Empty(t, linkedmap.Diff(a, b))
+	}
+}
@@ -0,0 +1,253 @@
+package linkedmap
+
+import (
+	"errors"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/quintans/faults"
+)
+
+// ErrPointerNotFound is returned when a JSON Pointer does not reference an existing value
+var ErrPointerNotFound = errors.New("pointer not found")
+
+// parsePointer splits an RFC 6901 JSON Pointer, like "/paths/~1users/get", in its unescaped reference tokens.
+// The empty pointer references the whole document and has no tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, faults.Errorf("invalid pointer %q: must start with '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		for j := 0; j < len(t); j++ {
+			if t[j] == '~' && (j+1 == len(t) || (t[j+1] != '0' && t[j+1] != '1')) {
+				return nil, faults.Errorf("invalid pointer %q: '~' must be followed by '0' or '1'", pointer)
+			}
+		}
+		tokens[i] = unescapeToken(t)
+	}
+	return tokens, nil
+}
+
+func unescapeToken(token string) string {
+	// "~01" is "~1" and not "/", so "~1" is replaced first
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+func escapeToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// arrayIndex converts the token to an index of an array with the size.
+// The index can be the size, to reference the position after the last element, if end is true.
+// The token "-" references that same position.
+func arrayIndex(token string, size int, end bool) (int, error) {
+	if token == "-" {
+		if end {
+			return size, nil
+		}
+		return 0, faults.Errorf("index '-' is after the last element: %w", ErrPointerNotFound)
+	}
+	// no signs or leading zeros
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.TrimLeft(token, "0123456789") != "" {
+		return 0, faults.Errorf("invalid array index %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i > size || (i == size && !end) {
+		return 0, faults.Errorf("array index %s out of bounds [0 - %d): %w", token, size, ErrPointerNotFound)
+	}
+	return i, nil
+}
+
+// GetPointer returns the value referenced by the RFC 6901 JSON Pointer.
+// Objects are *MapJSON and arrays are []any, as decoded by UnmarshalJSON.
+// It returns an error wrapping ErrPointerNotFound if there is no such value.
+func (m *MapJSON) GetPointer(pointer string) (any, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	var node any = m
+	for i, token := range tokens {
+		switch n := node.(type) {
+		case *MapJSON:
+			v, ok := n.Unwrap().Get(token)
+			if !ok {
+				return nil, faults.Errorf("%s: %w", pointerTo(tokens[:i+1]), ErrPointerNotFound)
+			}
+			node = v
+		case []any:
+			idx, err := arrayIndex(token, len(n), false)
+			if err != nil {
+				return nil, faults.Errorf("%s: %w", pointerTo(tokens[:i+1]), err)
+			}
+			node = n[idx]
+		default:
+			return nil, faults.Errorf("%s: %T is not an object or array: %w", pointerTo(tokens[:i]), node, ErrPointerNotFound)
+		}
+	}
+	return node, nil
+}
+
+// SetPointer sets the value referenced by the RFC 6901 JSON Pointer.
+// Missing intermediate objects are created, and existing keys keep their position.
+// An array element is replaced, and the index after the last element, or "-", appends to the array.
+func (m *MapJSON) SetPointer(pointer string, value any) error {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return faults.New("cannot set the root of the document")
+	}
+	_, err = setPointer(m, tokens, 0, value)
+	return err
+}
+
+// setPointer sets the value at the tokens from the position, returning the node, that changes if it is an array that grew
+func setPointer(node any, tokens []string, pos int, value any) (any, error) {
+	if pos == len(tokens) {
+		return value, nil
+	}
+
+	token := tokens[pos]
+	last := pos == len(tokens)-1
+	switch n := node.(type) {
+	case *MapJSON:
+		child, ok := n.Unwrap().Get(token)
+		if !ok && !last {
+			child = NewJSON()
+		}
+		v, err := setPointer(child, tokens, pos+1, value)
+		if err != nil {
+			return nil, err
+		}
+		n.Unwrap().Put(token, v)
+		return n, nil
+	case []any:
+		idx, err := arrayIndex(token, len(n), last)
+		if err != nil {
+			return nil, faults.Errorf("%s: %w", pointerTo(tokens[:pos+1]), err)
+		}
+		if idx == len(n) {
+			return append(n, value), nil
+		}
+		v, err := setPointer(n[idx], tokens, pos+1, value)
+		if err != nil {
+			return nil, err
+		}
+		n[idx] = v
+		return n, nil
+	default:
+		return nil, faults.Errorf("%s: %T is not an object or array", pointerTo(tokens[:pos]), node)
+	}
+}
+
+// DeletePointer removes the value referenced by the RFC 6901 JSON Pointer, returning it.
+// Removing an array element shifts the following ones.
+// It returns an error wrapping ErrPointerNotFound if there is no such value.
+func (m *MapJSON) DeletePointer(pointer string) (any, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, faults.New("cannot delete the root of the document")
+	}
+
+	parentPointer := pointerTo(tokens[:len(tokens)-1])
+	parent, err := m.GetPointer(parentPointer)
+	if err != nil {
+		return nil, err
+	}
+
+	token := tokens[len(tokens)-1]
+	switch p := parent.(type) {
+	case *MapJSON:
+		v, ok := p.Unwrap().Delete(token)
+		if !ok {
+			return nil, faults.Errorf("%s: %w", pointer, ErrPointerNotFound)
+		}
+		return v, nil
+	case []any:
+		idx, err := arrayIndex(token, len(p), false)
+		if err != nil {
+			return nil, faults.Errorf("%s: %w", pointer, err)
+		}
+		v := p[idx]
+		// the array shrinks, so the parent must hold the new slice
+		if err := m.SetPointer(parentPointer, slices.Delete(p, idx, idx+1)); err != nil {
+			return nil, err
+		}
+		return v, nil
+	default:
+		return nil, faults.Errorf("%s: %T is not an object or array: %w", parentPointer, parent, ErrPointerNotFound)
+	}
+}
+
+// pointerTo returns the JSON Pointer with the tokens
+func pointerTo(tokens []string) string {
+	var sb strings.Builder
+	for _, t := range tokens {
+		sb.WriteByte('/')
+		sb.WriteString(escapeToken(t))
+	}
+	return sb.String()
+}
+
+// Query iterates over the values matching the path, in document order, along with their JSON Pointers.
+// The path is a JSON Pointer where a "*" token matches every key of an object or every element of an array,
+// so "/paths/*/get/parameters/*/name" returns the names of the parameters of every GET operation.
+// A path that is not a valid pointer matches nothing.
+func (m *MapJSON) Query(path string) iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		tokens, err := parsePointer(path)
+		if err != nil {
+			return
+		}
+		query(m, tokens, nil, yield)
+	}
+}
+
+// query yields the values under node matching the tokens, where matched are the tokens that lead to the node.
+// It returns false if the iteration was stopped.
+func query(node any, tokens []string, matched []string, yield func(string, any) bool) bool {
+	if len(tokens) == 0 {
+		return yield(pointerTo(matched), node)
+	}
+
+	token, rest := tokens[0], tokens[1:]
+	switch n := node.(type) {
+	case *MapJSON:
+		if token == "*" {
+			for k, v := range n.Unwrap().Entries() {
+				if !query(v, rest, append(matched, k), yield) {
+					return false
+				}
+			}
+			return true
+		}
+		if v, ok := n.Unwrap().Get(token); ok {
+			return query(v, rest, append(matched, token), yield)
+		}
+	case []any:
+		if token == "*" {
+			for i, v := range n {
+				if !query(v, rest, append(matched, strconv.Itoa(i)), yield) {
+					return false
+				}
+			}
+			return true
+		}
+		if idx, err := arrayIndex(token, len(n), false); err == nil {
+			return query(n[idx], rest, append(matched, token), yield)
+		}
+	}
+	return true
+}
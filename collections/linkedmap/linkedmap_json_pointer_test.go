@@ -0,0 +1,138 @@
+package linkedmap_test
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/quintans/ds/collections/linkedmap"
+	"github.com/stretchr/testify/require"
+)
+
+const spec = `{"openapi":"3.0.0","paths":{"/users":{"get":{"parameters":[{"name":"limit"},{"name":"offset"}]},"post":{}},"/users/{id}":{"get":{"parameters":[{"name":"id"}]}}},"a~b":1,"":0}`
+
+func decodeSpec(t *testing.T) *linkedmap.MapJSON {
+	t.Helper()
+	om := linkedmap.NewJSON()
+	require.NoError(t, json.Unmarshal([]byte(spec), om))
+	return om
+}
+
+func TestGetPointer(t *testing.T) {
+	om := decodeSpec(t)
+
+	v, err := om.GetPointer("")
+	require.NoError(t, err)
+	require.Same(t, om, v)
+
+	v, err = om.GetPointer("/paths/~1users~1{id}/get/parameters/0/name")
+	require.NoError(t, err)
+	require.Equal(t, "id", v)
+
+	v, err = om.GetPointer("/a~0b")
+	require.NoError(t, err)
+	require.Equal(t, float64(1), v)
+
+	v, err = om.GetPointer("/")
+	require.NoError(t, err)
+	require.Equal(t, float64(0), v)
+
+	for _, p := range []string{"/missing", "/paths/~1users/get/parameters/2", "/paths/~1users/get/parameters/-", "/openapi/x"} {
+		_, err = om.GetPointer(p)
+		require.ErrorIs(t, err, linkedmap.ErrPointerNotFound, p)
+	}
+	for _, p := range []string{"paths", "/a~2b", "/a~", "/paths/~1users/get/parameters/01", "/paths/~1users/get/parameters/-1"} {
+		_, err = om.GetPointer(p)
+		require.Error(t, err, p)
+		require.NotErrorIs(t, err, linkedmap.ErrPointerNotFound, p)
+	}
+}
+
+func TestSetPointer(t *testing.T) {
+	om := decodeSpec(t)
+
+	// existing keys keep their position
+	require.NoError(t, om.SetPointer("/openapi", "3.1.0"))
+	require.Equal(t, []string{"openapi", "paths", "a~b", ""}, slices.Collect(om.Unwrap().Keys()))
+
+	// intermediate objects are created
+	require.NoError(t, om.SetPointer("/components/schemas/User", "x"))
+	v, err := om.GetPointer("/components/schemas/User")
+	require.NoError(t, err)
+	require.Equal(t, "x", v)
+
+	// arrays are replaced in place and appended
+	params := "/paths/~1users/get/parameters"
+	require.NoError(t, om.SetPointer(params+"/0/name", "max"))
+	require.NoError(t, om.SetPointer(params+"/-", "last"))
+	require.NoError(t, om.SetPointer(params+"/3", "after"))
+	v, err = om.GetPointer(params)
+	require.NoError(t, err)
+	require.Len(t, v, 4)
+	v, _ = om.GetPointer(params + "/0/name")
+	require.Equal(t, "max", v)
+	v, _ = om.GetPointer(params + "/3")
+	require.Equal(t, "after", v)
+
+	require.Error(t, om.SetPointer("", 1))
+	require.Error(t, om.SetPointer(params+"/9", 1))
+	require.Error(t, om.SetPointer(params+"/-/name", 1))
+	require.Error(t, om.SetPointer("/openapi/version", 1))
+}
+
+func TestDeletePointer(t *testing.T) {
+	om := decodeSpec(t)
+
+	params := "/paths/~1users/get/parameters"
+	v, err := om.DeletePointer(params + "/0")
+	require.NoError(t, err)
+	name, _ := v.(*linkedmap.MapJSON).Unwrap().Get("name")
+	require.Equal(t, "limit", name)
+	v, _ = om.GetPointer(params)
+	require.Len(t, v, 1)
+
+	_, err = om.DeletePointer("/paths/~1users")
+	require.NoError(t, err)
+	v, _ = om.GetPointer("/paths")
+	require.Equal(t, []string{"/users/{id}"}, slices.Collect(v.(*linkedmap.MapJSON).Unwrap().Keys()))
+
+	_, err = om.DeletePointer("/paths/~1users")
+	require.ErrorIs(t, err, linkedmap.ErrPointerNotFound)
+	_, err = om.DeletePointer(params + "/0")
+	require.ErrorIs(t, err, linkedmap.ErrPointerNotFound)
+	_, err = om.DeletePointer("")
+	require.Error(t, err)
+}
+
+func TestQuery(t *testing.T) {
+	om := decodeSpec(t)
+
+	got := maps.Collect(om.Query("/paths/*/get/parameters/*/name"))
+	require.Equal(t, map[string]any{
+		"/paths/~1users/get/parameters/0/name":       "limit",
+		"/paths/~1users/get/parameters/1/name":       "offset",
+		"/paths/~1users~1{id}/get/parameters/0/name": "id",
+	}, got)
+
+	var pointers []string
+	for p := range om.Query("/paths/*/*") {
+		pointers = append(pointers, p)
+	}
+	require.Equal(t, []string{"/paths/~1users/get", "/paths/~1users/post", "/paths/~1users~1{id}/get"}, pointers)
+
+	// the pointers returned reference the values
+	for p, v := range om.Query("/paths/*/get/parameters/*") {
+		got, err := om.GetPointer(p)
+		require.NoError(t, err)
+		require.Same(t, v, got)
+	}
+
+	// stops early
+	for range om.Query("/paths/*") {
+		break
+	}
+
+	require.Empty(t, maps.Collect(om.Query("/missing/*")))
+	require.Empty(t, maps.Collect(om.Query("no-slash")))
+}
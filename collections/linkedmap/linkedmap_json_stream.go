@@ -0,0 +1,519 @@
+package linkedmap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/quintans/faults"
+)
+
+type EncoderOption func(*Encoder)
+
+// WithIndent makes the encoder write each entry and array element in a new line, starting with prefix
+// and followed by one copy of indent per nesting level, like json.MarshalIndent.
+func WithIndent(prefix, indent string) EncoderOption {
+	return func(e *Encoder) {
+		e.prefix = prefix
+		e.indent = indent
+	}
+}
+
+// WithEscapeHTML sets if the characters <, > and & are escaped inside strings. Defaults to true, like encoding/json.
+func WithEscapeHTML(escape bool) EncoderOption {
+	return func(e *Encoder) {
+		e.escapeHTML = escape
+	}
+}
+
+// Encoder writes ordered JSON objects to a stream.
+// Nested objects and arrays are written as they are walked, so the document is never held whole in memory.
+type Encoder struct {
+	w          io.Writer
+	prefix     string
+	indent     string
+	escapeHTML bool
+	canonical  bool
+}
+
+func NewEncoder(w io.Writer, options ...EncoderOption) *Encoder {
+	e := &Encoder{
+		w:          w,
+		escapeHTML: true,
+	}
+	for _, opt := range options {
+		opt(e)
+	}
+	return e
+}
+
+// Encode writes the map as a JSON object followed by a newline, like json.Encoder.
+// If a value fails to encode, part of the object may already have been written.
+func (e *Encoder) Encode(m *MapJSON) error {
+	w := bufio.NewWriter(e.w)
+	s := e.state(w)
+	if err := s.encodeObject(m); err != nil {
+		return err
+	}
+	w.WriteByte('\n')
+	return faults.Wrap(w.Flush())
+}
+
+func (e *Encoder) state(w writer) *encodeState {
+	if e.canonical {
+		return &encodeState{w: w, canonical: true}
+	}
+	return &encodeState{
+		w:          w,
+		prefix:     e.prefix,
+		indent:     e.indent,
+		escapeHTML: e.escapeHTML,
+	}
+}
+
+type writer interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+}
+
+// encodeState holds the state of encoding one document.
+// Write errors are not checked, since the writers keep the first error, to be checked at the end.
+type encodeState struct {
+	w          writer
+	prefix     string
+	indent     string
+	escapeHTML bool
+	canonical  bool
+	depth      int
+	// buf holds the encoding of a single value that is not an object or array
+	buf bytes.Buffer
+}
+
+func (e *encodeState) indented() bool {
+	return e.prefix != "" || e.indent != ""
+}
+
+func (e *encodeState) newline() {
+	if !e.indented() {
+		return
+	}
+	e.w.WriteByte('\n')
+	e.w.WriteString(e.prefix)
+	for range e.depth {
+		e.w.WriteString(e.indent)
+	}
+}
+
+func (e *encodeState) encodeObject(m *MapJSON) error {
+	if m == nil {
+		e.w.WriteString("null")
+		return nil
+	}
+
+	entries := m.Unwrap().Entries()
+	if e.canonical {
+		entries = canonicalEntries(m)
+	}
+
+	e.w.WriteByte('{')
+	e.depth++
+	idx := 0
+	for k, v := range entries {
+		if idx > 0 {
+			e.w.WriteByte(',')
+		}
+		idx++
+		e.newline()
+		e.writeString(k)
+		e.w.WriteByte(':')
+		if e.indented() {
+			e.w.WriteByte(' ')
+		}
+		if err := e.encodeValue(v); err != nil {
+			return err
+		}
+	}
+	e.depth--
+	if idx > 0 {
+		e.newline()
+	}
+	e.w.WriteByte('}')
+	return nil
+}
+
+func (e *encodeState) encodeArray(a []any) error {
+	if a == nil {
+		e.w.WriteString("null")
+		return nil
+	}
+
+	e.w.WriteByte('[')
+	e.depth++
+	for i, v := range a {
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+		e.newline()
+		if err := e.encodeValue(v); err != nil {
+			return err
+		}
+	}
+	e.depth--
+	if len(a) > 0 {
+		e.newline()
+	}
+	e.w.WriteByte(']')
+	return nil
+}
+
+func (e *encodeState) encodeValue(v any) error {
+	switch t := v.(type) {
+	case *MapJSON:
+		return e.encodeObject(t)
+	case []any:
+		return e.encodeArray(t)
+	}
+	if e.canonical {
+		return e.encodeCanonical(v)
+	}
+
+	e.buf.Reset()
+	enc := json.NewEncoder(&e.buf)
+	enc.SetEscapeHTML(e.escapeHTML)
+	if e.indented() {
+		enc.SetIndent(e.prefix+strings.Repeat(e.indent, e.depth), e.indent)
+	}
+	if err := enc.Encode(v); err != nil {
+		return faults.Wrap(err)
+	}
+	e.w.Write(bytes.TrimSuffix(e.buf.Bytes(), []byte{'\n'}))
+	return nil
+}
+
+const hex = "0123456789abcdef"
+
+func (e *encodeState) writeString(s string) {
+	writeString(e.w, s, e.escapeHTML, e.canonical)
+}
+
+// writeString writes s as a JSON string, with the same escaping as encoding/json.
+// Invalid UTF-8 is replaced by U+FFFD.
+// In canonical mode only the quote, the backslash and the control characters are escaped, as RFC 8785 requires.
+func writeString(w writer, s string, escapeHTML, canonical bool) {
+	w.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && (!escapeHTML || (b != '<' && b != '>' && b != '&')) {
+				i++
+				continue
+			}
+			w.WriteString(s[start:i])
+			switch b {
+			case '"', '\\':
+				w.WriteByte('\\')
+				w.WriteByte(b)
+			case '\b':
+				w.WriteString(`\b`)
+			case '\f':
+				w.WriteString(`\f`)
+			case '\n':
+				w.WriteString(`\n`)
+			case '\r':
+				w.WriteString(`\r`)
+			case '\t':
+				w.WriteString(`\t`)
+			default:
+				w.WriteString(`\u00`)
+				w.WriteByte(hex[b>>4])
+				w.WriteByte(hex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			w.WriteString(s[start:i])
+			if canonical {
+				w.WriteString(string(utf8.RuneError))
+			} else {
+				w.WriteString(`\ufffd`)
+			}
+		case !canonical && (r == '\u2028' || r == '\u2029'):
+			// valid JSON, but not valid JavaScript
+			w.WriteString(s[start:i])
+			w.WriteString(`\u202`)
+			w.WriteByte(hex[r&0xF])
+		default:
+			i += size
+			continue
+		}
+		i += size
+		start = i
+	}
+	w.WriteString(s[start:])
+	w.WriteByte('"')
+}
+
+// DuplicateKeys is how the decoder handles a key repeated in the same object
+type DuplicateKeys int
+
+const (
+	// DuplicateLastWins keeps the last value, at the position of the first occurrence, like encoding/json
+	DuplicateLastWins DuplicateKeys = iota
+	// DuplicateFirstWins keeps the first value, ignoring the others
+	DuplicateFirstWins
+	// DuplicateError fails the decoding
+	DuplicateError
+)
+
+type numberMode int
+
+const (
+	numberFloat64 numberMode = iota
+	numberJSON
+	numberInt64
+)
+
+type DecoderOption func(*Decoder)
+
+// WithUseNumber decodes numbers as json.Number, keeping their exact text
+func WithUseNumber() DecoderOption {
+	return func(d *Decoder) {
+		d.numbers = numberJSON
+	}
+}
+
+// WithInt64 decodes integers that fit in an int64 as int64, and the other numbers as float64
+func WithInt64() DecoderOption {
+	return func(d *Decoder) {
+		d.numbers = numberInt64
+	}
+}
+
+// WithDuplicateKeys sets how keys repeated in the same object are handled. Defaults to DuplicateLastWins.
+func WithDuplicateKeys(policy DuplicateKeys) DecoderOption {
+	return func(d *Decoder) {
+		d.duplicates = policy
+	}
+}
+
+// WithMaxDepth limits the nesting of objects and arrays, where the top object is at depth 1. Defaults to no limit.
+func WithMaxDepth(depth int) DecoderOption {
+	return func(d *Decoder) {
+		d.maxDepth = depth
+	}
+}
+
+// WithDisallowTrailingData makes Decode fail if anything but whitespace follows the closing '}' of the object,
+// so the input must hold a single object.
+func WithDisallowTrailingData() DecoderOption {
+	return func(d *Decoder) {
+		d.disallowTrailing = true
+	}
+}
+
+// DecodeError is an error decoding the input, at the byte offset where it was found
+type DecodeError struct {
+	Offset int64
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("offset %d: %s", e.Offset, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Decoder reads ordered JSON objects from a stream, one token at a time.
+type Decoder struct {
+	dec              *json.Decoder
+	numbers          numberMode
+	duplicates       DuplicateKeys
+	maxDepth         int
+	disallowTrailing bool
+}
+
+func NewDecoder(r io.Reader, options ...DecoderOption) *Decoder {
+	d := &Decoder{dec: json.NewDecoder(r)}
+	for _, opt := range options {
+		opt(d)
+	}
+	if d.numbers != numberFloat64 {
+		d.dec.UseNumber()
+	}
+	return d
+}
+
+// Decode reads the next JSON object from the stream, adding its entries to the map in the order they appear.
+// Nested objects are decoded as *MapJSON and arrays as []any.
+// It returns io.EOF when there are no more values, and a *DecodeError, possibly wrapped, for invalid input.
+func (d *Decoder) Decode(m *MapJSON) error {
+	t, err := d.dec.Token()
+	if err == io.EOF {
+		return err
+	}
+	if err != nil {
+		return d.fail(err)
+	}
+
+	// must open with a delim token '{'
+	if delim, ok := t.(json.Delim); !ok || delim != '{' {
+		return d.failf("expect JSON object open with '{'")
+	}
+
+	// a zero map, as allocated by encoding/json, is not initialized
+	if m.entries == nil {
+		m.initialCapacity = defaultCapacity
+		m.Unwrap().Clear()
+	}
+
+	if err := d.parseObject(m, 1); err != nil {
+		return err
+	}
+
+	if d.disallowTrailing {
+		if _, err := d.dec.Token(); err != io.EOF {
+			return d.failf("unexpected data after the closing '}'")
+		}
+	}
+	return nil
+}
+
+// parseObject adds the entries of an object, whose '{' was already read, up to the closing '}'
+func (d *Decoder) parseObject(m *MapJSON, depth int) error {
+	if d.maxDepth > 0 && depth > d.maxDepth {
+		return d.failf("exceeded the maximum depth of %d", d.maxDepth)
+	}
+
+	om := m.Unwrap()
+	// only the keys of this object count as duplicates, not the ones already in the map
+	var seen map[string]struct{}
+	if d.duplicates != DuplicateLastWins {
+		seen = map[string]struct{}{}
+	}
+	for d.dec.More() {
+		t, err := d.dec.Token()
+		if err != nil {
+			return d.fail(err)
+		}
+		key, ok := t.(string)
+		if !ok {
+			return d.failf("key must be a string, got %T", t)
+		}
+
+		_, repeated := seen[key]
+		if repeated && d.duplicates == DuplicateError {
+			return d.failf("duplicate key %q", key)
+		}
+
+		val, err := d.parseValue(depth)
+		if err != nil {
+			return err
+		}
+		if repeated {
+			continue
+		}
+		if seen != nil {
+			seen[key] = struct{}{}
+		}
+		om.Put(key, val)
+	}
+
+	if _, err := d.dec.Token(); err != nil { // '}'
+		return d.fail(err)
+	}
+	return nil
+}
+
+// parseArray returns the values of an array, whose '[' was already read, up to the closing ']'
+func (d *Decoder) parseArray(depth int) ([]any, error) {
+	if d.maxDepth > 0 && depth > d.maxDepth {
+		return nil, d.failf("exceeded the maximum depth of %d", d.maxDepth)
+	}
+
+	ret := []any{}
+	for d.dec.More() {
+		v, err := d.parseValue(depth)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, v)
+	}
+
+	if _, err := d.dec.Token(); err != nil { // ']'
+		return nil, d.fail(err)
+	}
+	return ret, nil
+}
+
+// parseValue reads the next value, inside a container at the depth
+func (d *Decoder) parseValue(depth int) (any, error) {
+	t, err := d.dec.Token()
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	switch tok := t.(type) {
+	case json.Delim:
+		switch tok {
+		case '[':
+			return d.parseArray(depth + 1)
+		case '{':
+			om := NewJSON()
+			if err := d.parseObject(om, depth+1); err != nil {
+				return nil, err
+			}
+			return om, nil
+		default:
+			return nil, d.failf("unexpected delimiter %q", tok)
+		}
+	case json.Number:
+		return d.number(tok)
+	default:
+		return tok, nil
+	}
+}
+
+func (d *Decoder) number(n json.Number) (any, error) {
+	if d.numbers != numberInt64 {
+		return n, nil
+	}
+	if i, err := n.Int64(); err == nil {
+		return i, nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return nil, d.failf("invalid number %s", n)
+	}
+	return f, nil
+}
+
+// fail wraps the error with the offset where it happened
+func (d *Decoder) fail(err error) error {
+	offset := d.dec.InputOffset()
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	}
+	return faults.Wrap(&DecodeError{Offset: offset, Err: err})
+}
+
+func (d *Decoder) failf(format string, args ...any) error {
+	return d.fail(fmt.Errorf(format, args...))
+}
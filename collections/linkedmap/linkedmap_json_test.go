@@ -1,8 +1,12 @@
 package linkedmap_test
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
 	"slices"
+	"strings"
 	"testing"
 
 	"github.com/quintans/ds/collections/linkedmap"
@@ -23,3 +27,280 @@ func TestSerialisation(t *testing.T) {
 
 	require.Equal(t, s, string(j))
 }
+
+type Column struct {
+	Type     string `json:"type" yaml:"type"`
+	Nullable bool   `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+}
+
+func TestTypedSerialisation(t *testing.T) {
+	s := `{"id":{"type":"int"},"name":{"type":"text","nullable":true},"created":{"type":"timestamp"}}`
+	m := linkedmap.New[string, Column]()
+	err := json.Unmarshal([]byte(s), m)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"id", "name", "created"}, slices.Collect(m.Keys()))
+	v, ok := m.Get("name")
+	require.True(t, ok)
+	require.Equal(t, Column{Type: "text", Nullable: true}, v)
+
+	j, err := json.Marshal(m)
+	require.NoError(t, err)
+	require.Equal(t, s, string(j))
+}
+
+func TestTypedSerialisationInStruct(t *testing.T) {
+	type table struct {
+		Columns *linkedmap.Map[string, Column] `json:"columns"`
+	}
+	s := `{"columns":{"b":{"type":"int"},"a":{"type":"text"}}}`
+	var tb table
+	err := json.Unmarshal([]byte(s), &tb)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "a"}, slices.Collect(tb.Columns.Keys()))
+
+	j, err := json.Marshal(tb)
+	require.NoError(t, err)
+	require.Equal(t, s, string(j))
+}
+
+func TestIntKeySerialisation(t *testing.T) {
+	s := `{"3":"c","-1":"a","2":"b"}`
+	m := linkedmap.New[int8, string]()
+	err := json.Unmarshal([]byte(s), m)
+	require.NoError(t, err)
+	require.Equal(t, []int8{3, -1, 2}, slices.Collect(m.Keys()))
+
+	j, err := json.Marshal(m)
+	require.NoError(t, err)
+	require.Equal(t, s, string(j))
+
+	require.Error(t, json.Unmarshal([]byte(`{"300":"x"}`), linkedmap.New[int8, string]()))
+	require.Error(t, json.Unmarshal([]byte(`{"-1":"x"}`), linkedmap.New[uint, string]()))
+	require.Error(t, json.Unmarshal([]byte(`{"a":"x"}`), linkedmap.New[int, string]()))
+}
+
+type point struct {
+	X, Y int
+}
+
+func (p point) MarshalText() ([]byte, error) {
+	return fmt.Appendf(nil, "%d:%d", p.X, p.Y), nil
+}
+
+func (p *point) UnmarshalText(b []byte) error {
+	_, err := fmt.Sscanf(string(b), "%d:%d", &p.X, &p.Y)
+	return err
+}
+
+func TestTextMarshalerKeySerialisation(t *testing.T) {
+	s := `{"1:2":true,"0:0":false}`
+	m := linkedmap.New[point, bool]()
+	err := json.Unmarshal([]byte(s), m)
+	require.NoError(t, err)
+	require.Equal(t, []point{{1, 2}, {0, 0}}, slices.Collect(m.Keys()))
+
+	j, err := json.Marshal(m)
+	require.NoError(t, err)
+	require.Equal(t, s, string(j))
+}
+
+func TestTypedSerialisationErrors(t *testing.T) {
+	_, err := json.Marshal(linkedmap.New[float64, int]())
+	require.Error(t, err)
+
+	m := linkedmap.New[float64, int]()
+	m.Put(1.5, 1)
+	_, err = json.Marshal(m)
+	require.Error(t, err)
+	require.Error(t, json.Unmarshal([]byte(`{}`), linkedmap.New[float64, int]()))
+
+	require.Error(t, json.Unmarshal([]byte(`[1,2]`), linkedmap.New[string, int]()))
+	require.Error(t, json.Unmarshal([]byte(`{"a":"x"}`), linkedmap.New[string, int]()))
+
+	n := linkedmap.New[string, int]()
+	n.Put("a", 1)
+	require.NoError(t, json.Unmarshal([]byte(`null`), n))
+	require.Equal(t, 1, n.Size())
+}
+
+func TestSerialisationEscapesKeys(t *testing.T) {
+	keys := []string{`quo"te`, `back\slash`, "new\nline", "tab\t", "ctrl\x01", "<html>&", "line sep", "é€😀"}
+	om := linkedmap.NewJSON()
+	for i, k := range keys {
+		om.Unwrap().Put(k, float64(i))
+	}
+
+	j, err := json.Marshal(om)
+	require.NoError(t, err)
+	require.True(t, json.Valid(j), string(j))
+	require.Contains(t, string(j), `"ctrl\u0001"`)
+	require.Contains(t, string(j), `"\u003chtml\u003e\u0026"`)
+
+	other := linkedmap.NewJSON()
+	require.NoError(t, json.Unmarshal(j, other))
+	require.Equal(t, keys, slices.Collect(other.Unwrap().Keys()))
+
+	// the same as encoding/json for a single key
+	for _, k := range keys {
+		m := linkedmap.NewJSON()
+		m.Unwrap().Put(k, nil)
+		got, err := json.Marshal(m)
+		require.NoError(t, err)
+		want, err := json.Marshal(map[string]any{k: nil})
+		require.NoError(t, err)
+		require.Equal(t, string(want), string(got))
+	}
+}
+
+func TestEncoderIndent(t *testing.T) {
+	s := `{"one":1,"empty":{},"none":[],"sub":{"list":[1,{"a":"b"},[]],"value":{"x":1}},"text":"b"}`
+	om := linkedmap.NewJSON()
+	require.NoError(t, json.Unmarshal([]byte(s), om))
+
+	var out bytes.Buffer
+	err := linkedmap.NewEncoder(&out, linkedmap.WithIndent(">", "  ")).Encode(om)
+	require.NoError(t, err)
+
+	var want bytes.Buffer
+	require.NoError(t, json.Indent(&want, []byte(s), ">", "  "))
+	want.WriteByte('\n')
+	require.Equal(t, want.String(), out.String())
+}
+
+func TestEncoderIndentsPlainValues(t *testing.T) {
+	om := linkedmap.NewJSON()
+	om.Unwrap().Put("column", Column{Type: "int"})
+
+	var out bytes.Buffer
+	err := linkedmap.NewEncoder(&out, linkedmap.WithIndent("", "\t")).Encode(om)
+	require.NoError(t, err)
+	require.Equal(t, "{\n\t\"column\": {\n\t\t\"type\": \"int\"\n\t}\n}\n", out.String())
+}
+
+func TestEncoderEscapeHTML(t *testing.T) {
+	om := linkedmap.NewJSON()
+	om.Unwrap().Put("<k>", "a&b")
+
+	var out bytes.Buffer
+	require.NoError(t, linkedmap.NewEncoder(&out).Encode(om))
+	require.Equal(t, `{"\u003ck\u003e":"a\u0026b"}`+"\n", out.String())
+
+	out.Reset()
+	require.NoError(t, linkedmap.NewEncoder(&out, linkedmap.WithEscapeHTML(false)).Encode(om))
+	require.Equal(t, `{"<k>":"a&b"}`+"\n", out.String())
+}
+
+func TestDecoderStream(t *testing.T) {
+	in := strings.NewReader(`{"b":1,"a":{"d":2,"c":3}}
+{"z":[true,null]}`)
+	dec := linkedmap.NewDecoder(in)
+
+	first := linkedmap.NewJSON()
+	require.NoError(t, dec.Decode(first))
+	require.Equal(t, []string{"b", "a"}, slices.Collect(first.Unwrap().Keys()))
+	sub, _ := first.Unwrap().Get("a")
+	require.Equal(t, []string{"d", "c"}, slices.Collect(sub.(*linkedmap.MapJSON).Unwrap().Keys()))
+
+	second := &linkedmap.MapJSON{}
+	require.NoError(t, dec.Decode(second))
+	v, _ := second.Unwrap().Get("z")
+	require.Equal(t, []any{true, nil}, v)
+
+	require.ErrorIs(t, dec.Decode(linkedmap.NewJSON()), io.EOF)
+}
+
+func TestDecoderNumbers(t *testing.T) {
+	s := `{"id":9007199254740993,"ratio":0.5,"list":[12345678901234567,-3]}`
+
+	om := linkedmap.NewJSON()
+	require.NoError(t, linkedmap.NewDecoder(strings.NewReader(s)).Decode(om))
+	v, _ := om.Unwrap().Get("id")
+	require.IsType(t, float64(0), v)
+
+	om = linkedmap.NewJSON()
+	require.NoError(t, linkedmap.NewDecoder(strings.NewReader(s), linkedmap.WithUseNumber()).Decode(om))
+	v, _ = om.Unwrap().Get("id")
+	require.Equal(t, json.Number("9007199254740993"), v)
+	j, err := json.Marshal(om)
+	require.NoError(t, err)
+	require.Equal(t, s, string(j))
+
+	om = linkedmap.NewJSON()
+	require.NoError(t, linkedmap.NewDecoder(strings.NewReader(s), linkedmap.WithInt64()).Decode(om))
+	v, _ = om.Unwrap().Get("id")
+	require.Equal(t, int64(9007199254740993), v)
+	v, _ = om.Unwrap().Get("ratio")
+	require.Equal(t, 0.5, v)
+	v, _ = om.Unwrap().Get("list")
+	require.Equal(t, []any{int64(12345678901234567), int64(-3)}, v)
+	j, err = json.Marshal(om)
+	require.NoError(t, err)
+	require.Equal(t, s, string(j))
+}
+
+func TestDecoderDuplicateKeys(t *testing.T) {
+	s := `{"a":1,"b":2,"a":3}`
+	decode := func(options ...linkedmap.DecoderOption) (*linkedmap.MapJSON, error) {
+		om := linkedmap.NewJSON()
+		err := linkedmap.NewDecoder(strings.NewReader(s), options...).Decode(om)
+		return om, err
+	}
+
+	om, err := decode()
+	require.NoError(t, err)
+	j, _ := json.Marshal(om)
+	require.Equal(t, `{"a":3,"b":2}`, string(j))
+
+	om, err = decode(linkedmap.WithDuplicateKeys(linkedmap.DuplicateFirstWins))
+	require.NoError(t, err)
+	j, _ = json.Marshal(om)
+	require.Equal(t, `{"a":1,"b":2}`, string(j))
+
+	_, err = decode(linkedmap.WithDuplicateKeys(linkedmap.DuplicateError))
+	var decErr *linkedmap.DecodeError
+	require.ErrorAs(t, err, &decErr)
+	require.EqualValues(t, 16, decErr.Offset)
+
+	// keys already in the map are not duplicates
+	om = linkedmap.NewJSON()
+	om.Unwrap().Put("a", "old")
+	err = linkedmap.NewDecoder(strings.NewReader(`{"a":1}`), linkedmap.WithDuplicateKeys(linkedmap.DuplicateError)).Decode(om)
+	require.NoError(t, err)
+	v, _ := om.Unwrap().Get("a")
+	require.Equal(t, float64(1), v)
+}
+
+func TestDecoderMaxDepth(t *testing.T) {
+	decode := func(s string) error {
+		return linkedmap.NewDecoder(strings.NewReader(s), linkedmap.WithMaxDepth(2)).Decode(linkedmap.NewJSON())
+	}
+	require.NoError(t, decode(`{"a":{"b":1},"c":[1]}`))
+
+	var decErr *linkedmap.DecodeError
+	require.ErrorAs(t, decode(`{"a":{"b":{}}}`), &decErr)
+	require.EqualValues(t, 11, decErr.Offset)
+	require.ErrorAs(t, decode(`{"a":[[1]]}`), &decErr)
+}
+
+func TestDecoderTrailingData(t *testing.T) {
+	decode := func(s string, options ...linkedmap.DecoderOption) error {
+		return linkedmap.NewDecoder(strings.NewReader(s), options...).Decode(linkedmap.NewJSON())
+	}
+	require.NoError(t, decode(`{"a":1} {"b":2}`))
+	require.NoError(t, decode("{\"a\":1} \n", linkedmap.WithDisallowTrailingData()))
+
+	var decErr *linkedmap.DecodeError
+	require.ErrorAs(t, decode(`{"a":1} {"b":2}`, linkedmap.WithDisallowTrailingData()), &decErr)
+	require.ErrorAs(t, decode(`{"a":1}}`, linkedmap.WithDisallowTrailingData()), &decErr)
+	require.EqualValues(t, 7, decErr.Offset)
+}
+
+func TestDecoderSyntaxErrorOffset(t *testing.T) {
+	err := linkedmap.NewDecoder(strings.NewReader(`{"a":1,"b":tru}`)).Decode(linkedmap.NewJSON())
+	var decErr *linkedmap.DecodeError
+	require.ErrorAs(t, err, &decErr)
+	require.EqualValues(t, 15, decErr.Offset)
+	var syntaxErr *json.SyntaxError
+	require.ErrorAs(t, err, &syntaxErr)
+}
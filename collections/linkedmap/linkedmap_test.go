@@ -1,6 +1,8 @@
 package linkedmap_test
 
 import (
+	"maps"
+	"math/rand/v2"
 	"slices"
 	"testing"
 
@@ -127,3 +129,259 @@ func TestMapEntries(t *testing.T) {
 	}
 	require.Equal(t, 3, cnt)
 }
+
+func TestAccessOrder(t *testing.T) {
+	m := linkedmap.New(linkedmap.WithAccessOrder[string, int]())
+	m.Put("a", 1)
+	m.Put("b", 2)
+	m.Put("c", 3)
+
+	m.Get("a")
+	require.Equal(t, []string{"b", "c", "a"}, slices.Collect(m.Keys()))
+	m.Put("b", 20)
+	require.Equal(t, []string{"c", "a", "b"}, slices.Collect(m.Keys()))
+	m.Get("missing")
+	require.Equal(t, []string{"c", "a", "b"}, slices.Collect(m.Keys()))
+	require.Equal(t, []int{3, 1, 20}, slices.Collect(m.Values()))
+
+	// insertion order is kept without the option
+	m2 := linkedmap.New[string, int]()
+	m2.Put("a", 1)
+	m2.Put("b", 2)
+	m2.Get("a")
+	m2.Put("a", 10)
+	require.Equal(t, []string{"a", "b"}, slices.Collect(m2.Keys()))
+}
+
+func TestRemoveEldest(t *testing.T) {
+	var removed []string
+	var m *linkedmap.Map[string, int]
+	m = linkedmap.New(
+		linkedmap.WithAccessOrder[string, int](),
+		linkedmap.WithRemoveEldest(func(key string, value int) bool {
+			if m.Size() > 2 {
+				removed = append(removed, key)
+				return true
+			}
+			return false
+		}),
+	)
+	m.Put("a", 1)
+	m.Put("b", 2)
+	m.Get("a")
+	m.Put("c", 3)
+	require.Equal(t, []string{"b"}, removed)
+	require.Equal(t, []string{"a", "c"}, slices.Collect(m.Keys()))
+	require.False(t, m.ContainsKey("b"))
+
+	m.Put("a", 10)
+	m.Put("d", 4)
+	require.Equal(t, []string{"b", "c"}, removed)
+	require.Equal(t, []string{"a", "d"}, slices.Collect(m.Keys()))
+}
+
+func TestFirstLastPop(t *testing.T) {
+	m := linkedmap.New[string, int]()
+	_, _, ok := m.First()
+	require.False(t, ok)
+	_, _, ok = m.PopLast()
+	require.False(t, ok)
+
+	m.Put("a", 1)
+	m.Put("b", 2)
+	m.Put("c", 3)
+
+	k, v, ok := m.First()
+	require.True(t, ok)
+	require.Equal(t, "a", k)
+	require.Equal(t, 1, v)
+	k, v, ok = m.Last()
+	require.True(t, ok)
+	require.Equal(t, "c", k)
+	require.Equal(t, 3, v)
+
+	k, v, ok = m.PopFirst()
+	require.True(t, ok)
+	require.Equal(t, "a", k)
+	require.Equal(t, 1, v)
+	k, _, ok = m.PopLast()
+	require.True(t, ok)
+	require.Equal(t, "c", k)
+	require.Equal(t, []string{"b"}, slices.Collect(m.Keys()))
+	require.Equal(t, 1, m.Size())
+}
+
+func TestReverseIterators(t *testing.T) {
+	m := linkedmap.New[string, int]()
+	m.Put("a", 1)
+	m.Put("b", 2)
+	m.Put("c", 3)
+
+	require.Equal(t, []string{"c", "b", "a"}, slices.Collect(m.ReverseKeys()))
+	require.Equal(t, []int{3, 2, 1}, slices.Collect(m.ReverseValues()))
+	var keys []string
+	for k, v := range m.ReverseEntries() {
+		keys = append(keys, k)
+		require.Equal(t, int(k[0]-'a'+1), v)
+	}
+	require.Equal(t, []string{"c", "b", "a"}, keys)
+}
+
+func TestReorder(t *testing.T) {
+	m := linkedmap.New[string, int]()
+	m.Put("a", 1)
+	m.Put("b", 2)
+	m.Put("c", 3)
+
+	require.True(t, m.MoveToFront("c"))
+	require.Equal(t, []string{"c", "a", "b"}, slices.Collect(m.Keys()))
+	require.True(t, m.MoveToBack("c"))
+	require.Equal(t, []string{"a", "b", "c"}, slices.Collect(m.Keys()))
+	require.False(t, m.MoveToFront("x"))
+	require.False(t, m.MoveToBack("x"))
+
+	old, ok := m.PutFirst("z", 0)
+	require.False(t, ok)
+	require.Zero(t, old)
+	old, ok = m.PutFirst("b", 20)
+	require.True(t, ok)
+	require.Equal(t, 2, old)
+	require.Equal(t, []string{"b", "z", "a", "c"}, slices.Collect(m.Keys()))
+
+	require.True(t, m.InsertBefore("a", "y", 25))
+	require.True(t, m.InsertAfter("a", "x", 26))
+	require.Equal(t, []string{"b", "z", "y", "a", "x", "c"}, slices.Collect(m.Keys()))
+
+	// existing keys are moved
+	require.True(t, m.InsertAfter("c", "b", 200))
+	require.True(t, m.InsertBefore("z", "c", 300))
+	require.True(t, m.InsertBefore("a", "a", 100))
+	require.Equal(t, []string{"c", "z", "y", "a", "x", "b"}, slices.Collect(m.Keys()))
+	require.Equal(t, []int{300, 0, 25, 100, 26, 200}, slices.Collect(m.Values()))
+
+	require.False(t, m.InsertBefore("missing", "w", 0))
+	require.False(t, m.InsertAfter("missing", "w", 0))
+	require.False(t, m.ContainsKey("w"))
+	require.Equal(t, 6, m.Size())
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+	m := linkedmap.New[string, int]()
+	m.Put("a", 1)
+	m.Put("b", 2)
+	m.Put("c", 3)
+
+	c := m.Clone()
+	c.Delete("a")
+	c.Put("b", 20)
+	c.Put("d", 4)
+	m.Delete("c")
+
+	require.Equal(t, []string{"a", "b"}, slices.Collect(m.Keys()))
+	require.Equal(t, []int{1, 2}, slices.Collect(m.Values()))
+	require.Equal(t, []string{"b", "c", "d"}, slices.Collect(c.Keys()))
+	require.Equal(t, []int{20, 3, 4}, slices.Collect(c.Values()))
+}
+
+func TestCloneFunc(t *testing.T) {
+	m := linkedmap.New[string, []int]()
+	m.Put("a", []int{1, 2})
+
+	shallow := m.Clone()
+	deep := m.CloneFunc(slices.Clone[[]int])
+	v, _ := m.Get("a")
+	v[0] = 10
+
+	sv, _ := shallow.Get("a")
+	require.Equal(t, []int{10, 2}, sv)
+	dv, _ := deep.Get("a")
+	require.Equal(t, []int{1, 2}, dv)
+}
+
+// model is the expected state of a map: the keys in order and their values
+type model struct {
+	keys   []int
+	values map[int]int
+}
+
+func (m *model) clone() *model {
+	return &model{keys: slices.Clone(m.keys), values: maps.Clone(m.values)}
+}
+
+// mutate applies a random operation both to the map and to its model
+func mutate(r *rand.Rand, m *linkedmap.Map[int, int], md *model) {
+	k := r.IntN(20)
+	switch r.IntN(6) {
+	case 0, 1:
+		v := r.Int()
+		m.Put(k, v)
+		if _, ok := md.values[k]; !ok {
+			md.keys = append(md.keys, k)
+		}
+		md.values[k] = v
+	case 2:
+		m.Delete(k)
+		if _, ok := md.values[k]; ok {
+			md.keys = slices.DeleteFunc(md.keys, func(x int) bool { return x == k })
+			delete(md.values, k)
+		}
+	case 3:
+		if m.MoveToFront(k) {
+			md.keys = slices.DeleteFunc(md.keys, func(x int) bool { return x == k })
+			md.keys = slices.Insert(md.keys, 0, k)
+		}
+	case 4:
+		v := r.Int()
+		m.PutFirst(k, v)
+		md.keys = slices.DeleteFunc(md.keys, func(x int) bool { return x == k })
+		md.keys = slices.Insert(md.keys, 0, k)
+		md.values[k] = v
+	case 5:
+		if k, _, ok := m.PopLast(); ok {
+			md.keys = md.keys[:len(md.keys)-1]
+			delete(md.values, k)
+		}
+	}
+}
+
+func requireModel(t *testing.T, md *model, m *linkedmap.Map[int, int]) {
+	require.Equal(t, len(md.keys), m.Size())
+	// unlike require.Equal, slices.Equal treats the nil and the empty slices as equal
+	keys := slices.Collect(m.Keys())
+	require.True(t, slices.Equal(md.keys, keys), "expected keys %v, got %v", md.keys, keys)
+	for k, v := range m.Entries() {
+		require.Equal(t, md.values[k], v)
+	}
+}
+
+func TestClonePropertyIndependentMutations(t *testing.T) {
+	for seed := range uint64(50) {
+		r := rand.New(rand.NewPCG(seed, seed))
+		m := linkedmap.New[int, int]()
+		md := &model{values: map[int]int{}}
+		for range r.IntN(30) {
+			mutate(r, m, md)
+		}
+
+		c := m.Clone()
+		cmd := md.clone()
+		requireModel(t, cmd, c)
+
+		for range 100 {
+			if r.IntN(2) == 0 {
+				mutate(r, m, md)
+			} else {
+				mutate(r, c, cmd)
+			}
+			requireModel(t, md, m)
+			requireModel(t, cmd, c)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	m := linkedmap.New[int, string]()
+	m.Put(2, "b")
+	m.Put(1, "a")
+	require.Equal(t, "LinkedHashMap\nmap[2:b 1:a]", m.String())
+}
@@ -0,0 +1,305 @@
+package linkedmap
+
+import (
+	"github.com/quintans/faults"
+	"gopkg.in/yaml.v3"
+)
+
+// maxAliasNodes limits the nodes decoded through aliases in a MapJSON document,
+// so a small document with nested aliases cannot expand into a huge tree
+const maxAliasNodes = 1 << 20
+
+// MarshalYAML implements the yaml.Marshaler interface, serializing the map as a YAML mapping with the keys in order.
+func (m *Map[K, V]) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for k, v := range m.Entries() {
+		var key, value yaml.Node
+		if err := key.Encode(k); err != nil {
+			return nil, faults.Wrap(err)
+		}
+		if err := value.Encode(v); err != nil {
+			return nil, faults.Wrap(err)
+		}
+		node.Content = append(node.Content, &key, &value)
+	}
+	return node, nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface, adding the entries of the YAML mapping in the order they appear.
+// Keys and values are decoded straight into K and V, and merge keys ("<<") add the merged entries at their position.
+func (m *Map[K, V]) UnmarshalYAML(node *yaml.Node) error {
+	var d yamlDecoder
+	node, err := d.resolve(node)
+	if err != nil || isYAMLNull(node) {
+		return err
+	}
+	if node.Kind != yaml.MappingNode {
+		return faults.Errorf("line %d: expect a YAML mapping", node.Line)
+	}
+
+	pairs, err := d.pairs(node)
+	if err != nil {
+		return err
+	}
+
+	// a zero map, as allocated by the yaml package, is not initialized
+	if m.entries == nil {
+		m.initialCapacity = defaultCapacity
+		m.Clear()
+	}
+
+	for _, p := range pairs {
+		var k K
+		if err := p.key.Decode(&k); err != nil {
+			return faults.Wrap(err)
+		}
+		var v V
+		if err := p.value.Decode(&v); err != nil {
+			return faults.Wrap(err)
+		}
+		m.Put(k, v)
+	}
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface, serializing the map as a YAML mapping with the keys in order,
+// nested *MapJSON as mappings and []any as sequences.
+func (m *MapJSON) MarshalYAML() (any, error) {
+	return yamlNode(m)
+}
+
+func yamlNode(v any) (*yaml.Node, error) {
+	switch t := v.(type) {
+	case *MapJSON:
+		if t == nil {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+		}
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for k, v := range t.Unwrap().Entries() {
+			value, err := yamlNode(v)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}, value)
+		}
+		return node, nil
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, v := range t {
+			value, err := yamlNode(v)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, value)
+		}
+		return node, nil
+	default:
+		node := &yaml.Node{}
+		if err := node.Encode(v); err != nil {
+			return nil, faults.Wrap(err)
+		}
+		return node, nil
+	}
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface, adding the entries of the YAML mapping in the order they appear.
+// Nested mappings are decoded as *MapJSON and sequences as []any, while scalars keep the types of the yaml package,
+// like int, float64, bool or string. Keys are kept as written, and merge keys ("<<") add the merged entries at their position.
+func (m *MapJSON) UnmarshalYAML(node *yaml.Node) error {
+	var d yamlDecoder
+	node, err := d.resolve(node)
+	if err != nil || isYAMLNull(node) {
+		return err
+	}
+	if node.Kind != yaml.MappingNode {
+		return faults.Errorf("line %d: expect a YAML mapping", node.Line)
+	}
+
+	// a zero map, as allocated by the yaml package, is not initialized
+	if m.entries == nil {
+		m.initialCapacity = defaultCapacity
+		m.Unwrap().Clear()
+	}
+	return d.decodeMapping(m, node)
+}
+
+// yamlDecoder keeps the state of decoding a document through its aliases
+type yamlDecoder struct {
+	// expanding holds the anchored nodes being decoded, to detect aliases that contain themselves
+	expanding map[*yaml.Node]bool
+	// aliasDepth is the number of aliases followed to reach the node being decoded
+	aliasDepth int
+	// aliased counts the nodes decoded through aliases
+	aliased int
+}
+
+func (d *yamlDecoder) enter(node *yaml.Node) {
+	if d.expanding == nil {
+		d.expanding = map[*yaml.Node]bool{}
+	}
+	d.expanding[node] = true
+}
+
+func (d *yamlDecoder) leave(node *yaml.Node) {
+	delete(d.expanding, node)
+}
+
+// resolve returns the node referenced by documents and aliases
+func (d *yamlDecoder) resolve(node *yaml.Node) (*yaml.Node, error) {
+	for {
+		switch {
+		case node.Kind == yaml.DocumentNode && len(node.Content) > 0:
+			node = node.Content[0]
+		case node.Kind == yaml.AliasNode:
+			if d.expanding[node.Alias] {
+				return nil, faults.Errorf("line %d: alias %q contains itself", node.Line, node.Value)
+			}
+			node = node.Alias
+		default:
+			return node, nil
+		}
+	}
+}
+
+func isYAMLNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null"
+}
+
+func isYAMLMerge(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.ShortTag() == "!!merge"
+}
+
+type yamlPair struct {
+	key   *yaml.Node
+	value *yaml.Node
+	// merged is set for the pairs of a merged mapping
+	merged bool
+}
+
+// pairs returns the key and value nodes of a mapping, replacing each merge key with the entries of the merged mappings
+// that are not defined in the mapping itself or by a previous merge
+func (d *yamlDecoder) pairs(node *yaml.Node) ([]yamlPair, error) {
+	defined := map[string]bool{}
+	for i := 0; i < len(node.Content); i += 2 {
+		if !isYAMLMerge(node.Content[i]) {
+			defined[node.Content[i].Value] = true
+		}
+	}
+
+	pairs := make([]yamlPair, 0, len(node.Content)/2)
+	for i := 0; i < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if !isYAMLMerge(key) {
+			pairs = append(pairs, yamlPair{key: key, value: value})
+			continue
+		}
+
+		merged, err := d.resolve(value)
+		if err != nil {
+			return nil, err
+		}
+		mappings := []*yaml.Node{merged}
+		if merged.Kind == yaml.SequenceNode {
+			mappings = merged.Content
+		}
+		for _, mapping := range mappings {
+			mapping, err := d.resolve(mapping)
+			if err != nil {
+				return nil, err
+			}
+			if mapping.Kind != yaml.MappingNode {
+				return nil, faults.Errorf("line %d: merge key must reference mappings", key.Line)
+			}
+			d.enter(mapping)
+			mergedPairs, err := d.pairs(mapping)
+			d.leave(mapping)
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range mergedPairs {
+				if !defined[p.key.Value] {
+					defined[p.key.Value] = true
+					p.merged = true
+					pairs = append(pairs, p)
+				}
+			}
+		}
+	}
+	return pairs, nil
+}
+
+func (d *yamlDecoder) decodeMapping(m *MapJSON, node *yaml.Node) error {
+	d.enter(node)
+	defer d.leave(node)
+
+	pairs, err := d.pairs(node)
+	if err != nil {
+		return err
+	}
+	for _, p := range pairs {
+		key, err := d.resolve(p.key)
+		if err != nil {
+			return err
+		}
+		if key.Kind != yaml.ScalarNode {
+			return faults.Errorf("line %d: mapping keys must be scalars", key.Line)
+		}
+		// merged values are shared with the merged mapping, like aliases
+		if p.merged {
+			d.aliasDepth++
+		}
+		value, err := d.decodeValue(p.value)
+		if p.merged {
+			d.aliasDepth--
+		}
+		if err != nil {
+			return err
+		}
+		m.Unwrap().Put(key.Value, value)
+	}
+	return nil
+}
+
+func (d *yamlDecoder) decodeValue(node *yaml.Node) (any, error) {
+	if node.Kind == yaml.AliasNode {
+		d.aliasDepth++
+		defer func() { d.aliasDepth-- }()
+	}
+	node, err := d.resolve(node)
+	if err != nil {
+		return nil, err
+	}
+	if d.aliasDepth > 0 {
+		d.aliased++
+		if d.aliased > maxAliasNodes {
+			return nil, faults.Errorf("line %d: document expands too many aliases", node.Line)
+		}
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		om := NewJSON()
+		if err := d.decodeMapping(om, node); err != nil {
+			return nil, err
+		}
+		return om, nil
+	case yaml.SequenceNode:
+		d.enter(node)
+		defer d.leave(node)
+		a := make([]any, 0, len(node.Content))
+		for _, c := range node.Content {
+			v, err := d.decodeValue(c)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		return a, nil
+	default:
+		var v any
+		if err := node.Decode(&v); err != nil {
+			return nil, faults.Wrap(err)
+		}
+		return v, nil
+	}
+}
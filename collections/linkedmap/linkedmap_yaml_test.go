@@ -0,0 +1,135 @@
+package linkedmap_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/quintans/ds/collections/linkedmap"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const config = `name: service
+replicas: 3
+ratio: 0.5
+enabled: true
+nothing: null
+"123": quoted key
+env:
+    ZETA: z
+    ALPHA: a
+ports:
+    - 8080
+    - name: admin
+      port: 9090
+`
+
+func TestMapJSONYAML(t *testing.T) {
+	om := linkedmap.NewJSON()
+	require.NoError(t, yaml.Unmarshal([]byte(config), om))
+
+	require.Equal(t, []string{"name", "replicas", "ratio", "enabled", "nothing", "123", "env", "ports"}, slices.Collect(om.Unwrap().Keys()))
+	for pointer, want := range map[string]any{
+		"/replicas":     3,
+		"/ratio":        0.5,
+		"/enabled":      true,
+		"/nothing":      nil,
+		"/123":          "quoted key",
+		"/ports/0":      8080,
+		"/ports/1/port": 9090,
+		"/env/ALPHA":    "a",
+		"/ports/1/name": "admin",
+	} {
+		v, err := om.GetPointer(pointer)
+		require.NoError(t, err, pointer)
+		require.Equal(t, want, v, pointer)
+	}
+	env, _ := om.GetPointer("/env")
+	require.Equal(t, []string{"ZETA", "ALPHA"}, slices.Collect(env.(*linkedmap.MapJSON).Unwrap().Keys()))
+
+	out, err := yaml.Marshal(om)
+	require.NoError(t, err)
+	require.Equal(t, config, string(out))
+}
+
+func TestMapJSONYAMLFromJSON(t *testing.T) {
+	om := parseJSON(t, `{"b":{"true":"yes","y":[1,"2",null]},"a":1.5}`)
+	out, err := yaml.Marshal(om)
+	require.NoError(t, err)
+	require.Equal(t, `b:
+    "true": "yes"
+    y:
+        - 1
+        - "2"
+        - null
+a: 1.5
+`, string(out))
+
+	back := linkedmap.NewJSON()
+	require.NoError(t, yaml.Unmarshal(out, back))
+	require.Equal(t, `{"b":{"true":"yes","y":[1,"2",null]},"a":1.5}`, toJSON(t, back))
+}
+
+func TestMapJSONYAMLAliases(t *testing.T) {
+	s := `base: &base
+    host: localhost
+    port: 80
+dev:
+    port: 8080
+    <<: *base
+    debug: true
+list: &list [1, 2]
+copy: *list
+`
+	om := linkedmap.NewJSON()
+	require.NoError(t, yaml.Unmarshal([]byte(s), om))
+	require.Equal(t, `{"base":{"host":"localhost","port":80},"dev":{"port":8080,"host":"localhost","debug":true},"list":[1,2],"copy":[1,2]}`, toJSON(t, om))
+
+	// the decoded values are not shared
+	require.NoError(t, om.SetPointer("/dev/host", "remote"))
+	host, _ := om.GetPointer("/base/host")
+	require.Equal(t, "localhost", host)
+}
+
+func TestMapJSONYAMLAliasBomb(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("a0: &a0 [x, x, x, x, x, x, x, x, x, x]\n")
+	for i := 1; i < 9; i++ {
+		fmt.Fprintf(&sb, "a%d: &a%d [*a%d, *a%d, *a%d, *a%d, *a%d, *a%d, *a%d, *a%d, *a%d, *a%d]\n", i, i, i-1, i-1, i-1, i-1, i-1, i-1, i-1, i-1, i-1, i-1)
+	}
+	var node yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(sb.String()), &node))
+	require.Error(t, linkedmap.NewJSON().UnmarshalYAML(&node))
+}
+
+func TestTypedMapYAML(t *testing.T) {
+	s := `id:
+    type: int
+name:
+    type: text
+    nullable: true
+created:
+    type: timestamp
+`
+	m := linkedmap.New[string, Column]()
+	require.NoError(t, yaml.Unmarshal([]byte(s), m))
+	require.Equal(t, []string{"id", "name", "created"}, slices.Collect(m.Keys()))
+	v, _ := m.Get("name")
+	require.Equal(t, Column{Type: "text", Nullable: true}, v)
+
+	out, err := yaml.Marshal(m)
+	require.NoError(t, err)
+	require.Equal(t, s, string(out))
+
+	type table struct {
+		Columns *linkedmap.Map[int, string] `yaml:"columns"`
+	}
+	var tb table
+	require.NoError(t, yaml.Unmarshal([]byte("columns:\n    3: c\n    1: a\n"), &tb))
+	require.Equal(t, []int{3, 1}, slices.Collect(tb.Columns.Keys()))
+
+	require.Error(t, yaml.Unmarshal([]byte("- 1\n"), linkedmap.New[string, int]()))
+	require.Error(t, yaml.Unmarshal([]byte("a: x\n"), linkedmap.New[string, int]()))
+}
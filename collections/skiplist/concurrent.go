@@ -0,0 +1,314 @@
+package skiplist
+
+import (
+	"iter"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+type cnode[K, V any] struct {
+	key   K
+	value atomic.Pointer[V]
+	next  []atomic.Pointer[cnode[K, V]]
+
+	mu sync.Mutex
+	// marked is set when the node is being removed
+	marked atomic.Bool
+	// fullyLinked is set when the node is linked at all its levels
+	fullyLinked atomic.Bool
+}
+
+func (n *cnode[K, V]) topLevel() int {
+	return len(n.next) - 1
+}
+
+// ConcurrentMap is a sorted map backed by a lazy skip list, safe for concurrent use.
+// Lookups and iterations are lock free, and insertions and deletions only lock the nodes next to the key.
+// Iterations are weakly consistent: they reflect some of the changes made while iterating.
+// Unlike Map, it does not keep the span counts, so there is no access by rank.
+type ConcurrentMap[K, V any] struct {
+	cmp      func(a, b K) int
+	head     *cnode[K, V]
+	maxLevel int
+	size     atomic.Int64
+
+	randMu sync.Mutex
+	rand   *rand.Rand
+}
+
+// NewConcurrent creates a concurrent map sorted by cmp, that returns a negative number when a < b, a positive number when a > b and zero when a == b.
+func NewConcurrent[K, V any](cmp func(a, b K) int, options ...Option) *ConcurrentMap[K, V] {
+	c := newConfig(options)
+	head := &cnode[K, V]{next: make([]atomic.Pointer[cnode[K, V]], c.maxLevel)}
+	head.fullyLinked.Store(true)
+	return &ConcurrentMap[K, V]{
+		cmp:      cmp,
+		head:     head,
+		maxLevel: c.maxLevel,
+		rand:     c.random(),
+	}
+}
+
+// Size returns the number of entries
+func (m *ConcurrentMap[K, V]) Size() int {
+	return int(m.size.Load())
+}
+
+func (m *ConcurrentMap[K, V]) randomLevel() int {
+	m.randMu.Lock()
+	defer m.randMu.Unlock()
+	return randomLevel(m.rand, m.maxLevel)
+}
+
+// find fills the predecessors and successors of the key at every level,
+// returning the highest level where the key was found, or -1
+func (m *ConcurrentMap[K, V]) find(key K, preds, succs []*cnode[K, V]) int {
+	found := -1
+	pred := m.head
+	for level := m.maxLevel - 1; level >= 0; level-- {
+		curr := pred.next[level].Load()
+		for curr != nil && m.cmp(curr.key, key) < 0 {
+			pred = curr
+			curr = pred.next[level].Load()
+		}
+		if found == -1 && curr != nil && m.cmp(curr.key, key) == 0 {
+			found = level
+		}
+		preds[level] = pred
+		succs[level] = curr
+	}
+	return found
+}
+
+// lock locks the distinct predecessors up to the level, returning the unlock function.
+// Predecessors at lower levels are never before the ones at higher levels, so repeated ones are adjacent.
+func lock[K, V any](preds []*cnode[K, V], top int) func() {
+	var prev *cnode[K, V]
+	for level := 0; level <= top; level++ {
+		if preds[level] != prev {
+			preds[level].mu.Lock()
+			prev = preds[level]
+		}
+	}
+	return func() {
+		var prev *cnode[K, V]
+		for level := 0; level <= top; level++ {
+			if preds[level] != prev {
+				preds[level].mu.Unlock()
+				prev = preds[level]
+			}
+		}
+	}
+}
+
+// Put sets the value of the key, returning true if the key is new
+func (m *ConcurrentMap[K, V]) Put(key K, value V) bool {
+	top := m.randomLevel() - 1
+	preds := make([]*cnode[K, V], m.maxLevel)
+	succs := make([]*cnode[K, V], m.maxLevel)
+	for {
+		if found := m.find(key, preds, succs); found != -1 {
+			n := succs[found]
+			if !n.marked.Load() {
+				for !n.fullyLinked.Load() {
+					// the node is being inserted
+					runtime.Gosched()
+				}
+				n.value.Store(&value)
+				return false
+			}
+			// the node is being removed, try again
+			continue
+		}
+
+		unlock := lock(preds, top)
+		valid := true
+		for level := 0; valid && level <= top; level++ {
+			pred, succ := preds[level], succs[level]
+			valid = !pred.marked.Load() && (succ == nil || !succ.marked.Load()) && pred.next[level].Load() == succ
+		}
+		if !valid {
+			unlock()
+			continue
+		}
+
+		n := &cnode[K, V]{key: key, next: make([]atomic.Pointer[cnode[K, V]], top+1)}
+		n.value.Store(&value)
+		for level := 0; level <= top; level++ {
+			n.next[level].Store(succs[level])
+		}
+		for level := 0; level <= top; level++ {
+			preds[level].next[level].Store(n)
+		}
+		n.fullyLinked.Store(true)
+		m.size.Add(1)
+		unlock()
+		return true
+	}
+}
+
+// Get returns the value of the key
+func (m *ConcurrentMap[K, V]) Get(key K) (V, bool) {
+	n := m.ceiling(key)
+	if n == nil || m.cmp(n.key, key) != 0 {
+		var zero V
+		return zero, false
+	}
+	return *n.value.Load(), true
+}
+
+// Contains checks if the key exists
+func (m *ConcurrentMap[K, V]) Contains(key K) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+// Delete removes the key, returning true if it existed
+func (m *ConcurrentMap[K, V]) Delete(key K) bool {
+	preds := make([]*cnode[K, V], m.maxLevel)
+	succs := make([]*cnode[K, V], m.maxLevel)
+	var victim *cnode[K, V]
+	marked := false
+	for {
+		found := m.find(key, preds, succs)
+		if !marked {
+			if found == -1 {
+				return false
+			}
+			victim = succs[found]
+			// only fully linked nodes found at their top level can be removed
+			if !victim.fullyLinked.Load() || victim.topLevel() != found || victim.marked.Load() {
+				return false
+			}
+			victim.mu.Lock()
+			if victim.marked.Load() {
+				victim.mu.Unlock()
+				return false
+			}
+			victim.marked.Store(true)
+			marked = true
+		}
+
+		top := victim.topLevel()
+		unlock := lock(preds, top)
+		valid := true
+		for level := 0; valid && level <= top; level++ {
+			pred := preds[level]
+			valid = !pred.marked.Load() && pred.next[level].Load() == victim
+		}
+		if !valid {
+			unlock()
+			continue
+		}
+
+		for level := top; level >= 0; level-- {
+			preds[level].next[level].Store(victim.next[level].Load())
+		}
+		victim.mu.Unlock()
+		m.size.Add(-1)
+		unlock()
+		return true
+	}
+}
+
+// First returns the entry with the lowest key
+func (m *ConcurrentMap[K, V]) First() (K, V, bool) {
+	return m.entry(m.successor(m.head))
+}
+
+// Floor returns the entry with the greatest key less than or equal to the key
+func (m *ConcurrentMap[K, V]) Floor(key K) (K, V, bool) {
+	pred := m.last(func(k K) bool {
+		return m.cmp(k, key) <= 0
+	})
+	// nodes being inserted or removed are skipped, searching again before them
+	for pred != m.head && !m.live(pred) {
+		before := pred.key
+		pred = m.last(func(k K) bool {
+			return m.cmp(k, before) < 0
+		})
+	}
+	if pred == m.head {
+		return m.entry(nil)
+	}
+	return m.entry(pred)
+}
+
+// last returns the last node whose key is accepted, or the head if there is none
+func (m *ConcurrentMap[K, V]) last(accept func(K) bool) *cnode[K, V] {
+	pred := m.head
+	for level := m.maxLevel - 1; level >= 0; level-- {
+		for curr := pred.next[level].Load(); curr != nil && accept(curr.key); curr = pred.next[level].Load() {
+			pred = curr
+		}
+	}
+	return pred
+}
+
+// Ceiling returns the entry with the least key greater than or equal to the key
+func (m *ConcurrentMap[K, V]) Ceiling(key K) (K, V, bool) {
+	return m.entry(m.ceiling(key))
+}
+
+// ceiling returns the first live node with a key greater than or equal to the key
+func (m *ConcurrentMap[K, V]) ceiling(key K) *cnode[K, V] {
+	pred := m.last(func(k K) bool {
+		return m.cmp(k, key) < 0
+	})
+	return m.successor(pred)
+}
+
+// successor returns the next live node at the bottom level
+func (m *ConcurrentMap[K, V]) successor(n *cnode[K, V]) *cnode[K, V] {
+	curr := n.next[0].Load()
+	for curr != nil && !m.live(curr) {
+		curr = curr.next[0].Load()
+	}
+	return curr
+}
+
+func (m *ConcurrentMap[K, V]) live(n *cnode[K, V]) bool {
+	return n.fullyLinked.Load() && !n.marked.Load()
+}
+
+// All iterates over all entries in ascending order of the keys
+func (m *ConcurrentMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.forward(m.successor(m.head), nil, yield)
+	}
+}
+
+// Range iterates in ascending order over the entries with keys between from, inclusive, and to, exclusive
+func (m *ConcurrentMap[K, V]) Range(from, to K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.forward(m.ceiling(from), func(key K) bool {
+			return m.cmp(key, to) < 0
+		}, yield)
+	}
+}
+
+// From iterates in ascending order over the entries with keys greater than or equal to the key
+func (m *ConcurrentMap[K, V]) From(key K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.forward(m.ceiling(key), nil, yield)
+	}
+}
+
+func (m *ConcurrentMap[K, V]) forward(n *cnode[K, V], accept func(K) bool, yield func(K, V) bool) {
+	for ; n != nil && (accept == nil || accept(n.key)); n = m.successor(n) {
+		if !yield(n.key, *n.value.Load()) {
+			return
+		}
+	}
+}
+
+func (m *ConcurrentMap[K, V]) entry(n *cnode[K, V]) (K, V, bool) {
+	if n == nil {
+		var k K
+		var v V
+		return k, v, false
+	}
+	return n.key, *n.value.Load(), true
+}
@@ -0,0 +1,89 @@
+package skiplist_test
+
+import (
+	"cmp"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quintans/ds/collections/skiplist"
+)
+
+func TestConcurrentMap(t *testing.T) {
+	m := skiplist.NewConcurrent[int, string](cmp.Compare[int], skiplist.WithSeed(1))
+	assert.True(t, m.Put(20, "b"))
+	assert.True(t, m.Put(10, "a"))
+	assert.True(t, m.Put(30, "c"))
+	assert.False(t, m.Put(20, "B"))
+	assert.Equal(t, 3, m.Size())
+
+	v, ok := m.Get(20)
+	require.True(t, ok)
+	assert.Equal(t, "B", v)
+	assert.False(t, m.Contains(15))
+
+	k, _, ok := m.Floor(25)
+	require.True(t, ok)
+	assert.Equal(t, 20, k)
+	_, _, ok = m.Floor(5)
+	assert.False(t, ok)
+	k, _, ok = m.Ceiling(25)
+	require.True(t, ok)
+	assert.Equal(t, 30, k)
+	k, _, ok = m.First()
+	require.True(t, ok)
+	assert.Equal(t, 10, k)
+
+	assert.Equal(t, []int{10, 20, 30}, collectKeys(m.All()))
+	assert.Equal(t, []int{20}, collectKeys(m.Range(15, 30)))
+	assert.Equal(t, []int{20, 30}, collectKeys(m.From(20)))
+
+	assert.True(t, m.Delete(20))
+	assert.False(t, m.Delete(20))
+	assert.Equal(t, []int{10, 30}, collectKeys(m.All()))
+	assert.Equal(t, 2, m.Size())
+}
+
+func TestConcurrentMapParallel(t *testing.T) {
+	const (
+		workers = 8
+		keys    = 1000
+	)
+	m := skiplist.NewConcurrent[int, int](cmp.Compare[int])
+
+	// each worker owns the keys congruent to its id, adding all and removing the odd ones
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Go(func() {
+			for k := w; k < keys; k += workers {
+				m.Put(k, k)
+			}
+			for k := w; k < keys; k += workers {
+				if k%2 == 1 {
+					m.Delete(k)
+				}
+				// readers running against the writers
+				m.Get(k)
+				m.Floor(k)
+			}
+		})
+	}
+	// contended updates of the same keys
+	for range workers {
+		wg.Go(func() {
+			for k := range 100 {
+				m.Put(k*2, k*2)
+			}
+		})
+	}
+	wg.Wait()
+
+	var want []int
+	for k := 0; k < keys; k += 2 {
+		want = append(want, k)
+	}
+	assert.Equal(t, want, collectKeys(m.All()))
+	assert.Equal(t, len(want), m.Size())
+}
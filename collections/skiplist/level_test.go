@@ -0,0 +1,29 @@
+package skiplist
+
+import (
+	"cmp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func levels(m *Map[int, int]) []int {
+	var levels []int
+	for x := m.head.links[0].next; x != nil; x = x.links[0].next {
+		levels = append(levels, len(x.links))
+	}
+	return levels
+}
+
+func TestSeedIsDeterministic(t *testing.T) {
+	build := func(seed uint64) *Map[int, int] {
+		m := New[int, int](cmp.Compare[int], WithSeed(seed))
+		for i := range 200 {
+			m.Put(i, i)
+		}
+		return m
+	}
+
+	assert.Equal(t, levels(build(42)), levels(build(42)))
+	assert.NotEqual(t, levels(build(42)), levels(build(43)))
+}
@@ -0,0 +1,360 @@
+package skiplist
+
+import (
+	"iter"
+	"math/rand/v2"
+)
+
+const (
+	defaultMaxLevel = 32
+	// each level holds about a quarter of the nodes of the level below
+	levelMask = 3
+)
+
+type config struct {
+	seed     *uint64
+	maxLevel int
+}
+
+type Option func(*config)
+
+// WithSeed sets the seed of the generator of the node levels, making the shape of the list deterministic
+func WithSeed(seed uint64) Option {
+	return func(c *config) {
+		c.seed = &seed
+	}
+}
+
+// WithMaxLevel sets the maximum number of levels. Defaults to 32, good for 4^32 entries
+func WithMaxLevel(maxLevel int) Option {
+	return func(c *config) {
+		c.maxLevel = maxLevel
+	}
+}
+
+func newConfig(options []Option) config {
+	c := config{maxLevel: defaultMaxLevel}
+	for _, opt := range options {
+		opt(&c)
+	}
+	c.maxLevel = max(c.maxLevel, 1)
+	return c
+}
+
+func (c config) random() *rand.Rand {
+	if c.seed != nil {
+		return rand.New(rand.NewPCG(*c.seed, *c.seed))
+	}
+	return rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+}
+
+// randomLevel returns a level between 1 and maxLevel, with a probability of 1/4 of going up each level
+func randomLevel(r *rand.Rand, maxLevel int) int {
+	level := 1
+	for level < maxLevel && r.Uint32()&levelMask == 0 {
+		level++
+	}
+	return level
+}
+
+type link[K, V any] struct {
+	next *node[K, V]
+	// span is the number of entries between the node and next, counting next
+	span int
+}
+
+type node[K, V any] struct {
+	key   K
+	value V
+	prev  *node[K, V]
+	links []link[K, V]
+}
+
+// Map is a sorted map backed by a skip list.
+// Lookups, insertions and deletions are O(log n), and so is the access by rank, through the span counts of the links.
+// It is not safe for concurrent use, see ConcurrentMap.
+type Map[K, V any] struct {
+	cmp      func(a, b K) int
+	head     *node[K, V]
+	tail     *node[K, V]
+	level    int
+	size     int
+	maxLevel int
+	rand     *rand.Rand
+}
+
+// New creates a map sorted by cmp, that returns a negative number when a < b, a positive number when a > b and zero when a == b.
+func New[K, V any](cmp func(a, b K) int, options ...Option) *Map[K, V] {
+	c := newConfig(options)
+	m := &Map[K, V]{
+		cmp:      cmp,
+		maxLevel: c.maxLevel,
+		rand:     c.random(),
+	}
+	m.Clear()
+	return m
+}
+
+// Clear removes all entries, O(1)
+func (m *Map[K, V]) Clear() {
+	m.head = &node[K, V]{links: make([]link[K, V], m.maxLevel)}
+	m.tail = nil
+	m.level = 1
+	m.size = 0
+}
+
+// Size returns the number of entries
+func (m *Map[K, V]) Size() int {
+	return m.size
+}
+
+// Put sets the value of the key, returning true if the key is new, O(log n)
+func (m *Map[K, V]) Put(key K, value V) bool {
+	update := make([]*node[K, V], m.maxLevel)
+	rank := make([]int, m.maxLevel)
+
+	x := m.head
+	for i := m.level - 1; i >= 0; i-- {
+		if i < m.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for next := x.links[i].next; next != nil && m.cmp(next.key, key) < 0; next = x.links[i].next {
+			rank[i] += x.links[i].span
+			x = next
+		}
+		update[i] = x
+	}
+
+	if next := x.links[0].next; next != nil && m.cmp(next.key, key) == 0 {
+		next.value = value
+		return false
+	}
+
+	level := randomLevel(m.rand, m.maxLevel)
+	if level > m.level {
+		for i := m.level; i < level; i++ {
+			update[i] = m.head
+			m.head.links[i].span = m.size
+		}
+		m.level = level
+	}
+
+	x = &node[K, V]{key: key, value: value, links: make([]link[K, V], level)}
+	for i := range level {
+		x.links[i].next = update[i].links[i].next
+		update[i].links[i].next = x
+		x.links[i].span = update[i].links[i].span - (rank[0] - rank[i])
+		update[i].links[i].span = rank[0] - rank[i] + 1
+	}
+	// the links above the new node now go over it
+	for i := level; i < m.level; i++ {
+		update[i].links[i].span++
+	}
+
+	if update[0] != m.head {
+		x.prev = update[0]
+	}
+	if x.links[0].next != nil {
+		x.links[0].next.prev = x
+	} else {
+		m.tail = x
+	}
+	m.size++
+	return true
+}
+
+// Get returns the value of the key, O(log n)
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	n := m.ceiling(key)
+	if n == nil || m.cmp(n.key, key) != 0 {
+		var zero V
+		return zero, false
+	}
+	return n.value, true
+}
+
+// Contains checks if the key exists, O(log n)
+func (m *Map[K, V]) Contains(key K) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+// Delete removes the key, returning true if it existed, O(log n)
+func (m *Map[K, V]) Delete(key K) bool {
+	update := make([]*node[K, V], m.maxLevel)
+	x := m.head
+	for i := m.level - 1; i >= 0; i-- {
+		for next := x.links[i].next; next != nil && m.cmp(next.key, key) < 0; next = x.links[i].next {
+			x = next
+		}
+		update[i] = x
+	}
+
+	x = x.links[0].next
+	if x == nil || m.cmp(x.key, key) != 0 {
+		return false
+	}
+
+	for i := range m.level {
+		if update[i].links[i].next == x {
+			update[i].links[i].span += x.links[i].span - 1
+			update[i].links[i].next = x.links[i].next
+		} else {
+			update[i].links[i].span--
+		}
+	}
+	if x.links[0].next != nil {
+		x.links[0].next.prev = x.prev
+	} else {
+		m.tail = x.prev
+	}
+	for m.level > 1 && m.head.links[m.level-1].next == nil {
+		m.level--
+	}
+	m.size--
+	return true
+}
+
+// First returns the entry with the lowest key, O(1)
+func (m *Map[K, V]) First() (K, V, bool) {
+	return entry(m.head.links[0].next)
+}
+
+// Last returns the entry with the highest key, O(1)
+func (m *Map[K, V]) Last() (K, V, bool) {
+	return entry(m.tail)
+}
+
+// Floor returns the entry with the greatest key less than or equal to the key, O(log n)
+func (m *Map[K, V]) Floor(key K) (K, V, bool) {
+	x := m.head
+	for i := m.level - 1; i >= 0; i-- {
+		for next := x.links[i].next; next != nil && m.cmp(next.key, key) <= 0; next = x.links[i].next {
+			x = next
+		}
+	}
+	if x == m.head {
+		return entry[K, V](nil)
+	}
+	return entry(x)
+}
+
+// Ceiling returns the entry with the least key greater than or equal to the key, O(log n)
+func (m *Map[K, V]) Ceiling(key K) (K, V, bool) {
+	return entry(m.ceiling(key))
+}
+
+func (m *Map[K, V]) ceiling(key K) *node[K, V] {
+	x := m.head
+	for i := m.level - 1; i >= 0; i-- {
+		for next := x.links[i].next; next != nil && m.cmp(next.key, key) < 0; next = x.links[i].next {
+			x = next
+		}
+	}
+	return x.links[0].next
+}
+
+// Rank returns the index of the key in the sorted order, O(log n)
+func (m *Map[K, V]) Rank(key K) (int, bool) {
+	rank := 0
+	x := m.head
+	for i := m.level - 1; i >= 0; i-- {
+		for next := x.links[i].next; next != nil && m.cmp(next.key, key) <= 0; next = x.links[i].next {
+			rank += x.links[i].span
+			x = next
+		}
+	}
+	if x == m.head || m.cmp(x.key, key) != 0 {
+		return -1, false
+	}
+	return rank - 1, true
+}
+
+// Select returns the entry at the index in the sorted order, O(log n)
+func (m *Map[K, V]) Select(index int) (K, V, bool) {
+	return entry(m.at(index))
+}
+
+func (m *Map[K, V]) at(index int) *node[K, V] {
+	if index < 0 || index >= m.size {
+		return nil
+	}
+	traversed := 0
+	x := m.head
+	for i := m.level - 1; i >= 0; i-- {
+		for x.links[i].next != nil && traversed+x.links[i].span <= index+1 {
+			traversed += x.links[i].span
+			x = x.links[i].next
+		}
+		if traversed == index+1 {
+			return x
+		}
+	}
+	return nil
+}
+
+// All iterates over all entries in ascending order of the keys
+func (m *Map[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		forward(m.head.links[0].next, nil, yield)
+	}
+}
+
+// Backward iterates over all entries in descending order of the keys
+func (m *Map[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for x := m.tail; x != nil; x = x.prev {
+			if !yield(x.key, x.value) {
+				return
+			}
+		}
+	}
+}
+
+// Range iterates in ascending order over the entries with keys between from, inclusive, and to, exclusive
+func (m *Map[K, V]) Range(from, to K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		forward(m.ceiling(from), func(key K) bool {
+			return m.cmp(key, to) < 0
+		}, yield)
+	}
+}
+
+// From iterates in ascending order over the entries with keys greater than or equal to the key
+func (m *Map[K, V]) From(key K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		forward(m.ceiling(key), nil, yield)
+	}
+}
+
+// RangeByRank iterates in ascending order over the entries with index between from, inclusive, and to, exclusive
+func (m *Map[K, V]) RangeByRank(from, to int) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		from = max(from, 0)
+		x := m.at(from)
+		for i := from; x != nil && i < to; i++ {
+			if !yield(x.key, x.value) {
+				return
+			}
+			x = x.links[0].next
+		}
+	}
+}
+
+// forward yields the entries from the node while the keys are accepted
+func forward[K, V any](x *node[K, V], accept func(K) bool, yield func(K, V) bool) {
+	for ; x != nil && (accept == nil || accept(x.key)); x = x.links[0].next {
+		if !yield(x.key, x.value) {
+			return
+		}
+	}
+}
+
+func entry[K, V any](x *node[K, V]) (K, V, bool) {
+	if x == nil {
+		var k K
+		var v V
+		return k, v, false
+	}
+	return x.key, x.value, true
+}
@@ -0,0 +1,158 @@
+package skiplist_test
+
+import (
+	"cmp"
+	"iter"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quintans/ds/collections/skiplist"
+)
+
+func TestPutGetDelete(t *testing.T) {
+	m := skiplist.New[string, int](cmp.Compare[string], skiplist.WithSeed(1))
+	assert.True(t, m.Put("b", 2))
+	assert.True(t, m.Put("a", 1))
+	assert.True(t, m.Put("c", 3))
+	assert.False(t, m.Put("b", 20))
+	assert.Equal(t, 3, m.Size())
+
+	v, ok := m.Get("b")
+	require.True(t, ok)
+	assert.Equal(t, 20, v)
+	_, ok = m.Get("d")
+	assert.False(t, ok)
+	assert.True(t, m.Contains("a"))
+
+	assert.True(t, m.Delete("a"))
+	assert.False(t, m.Delete("a"))
+	assert.False(t, m.Contains("a"))
+	assert.Equal(t, 2, m.Size())
+
+	m.Clear()
+	assert.Equal(t, 0, m.Size())
+	_, _, ok = m.First()
+	assert.False(t, ok)
+}
+
+func TestNavigation(t *testing.T) {
+	m := skiplist.New[int, string](cmp.Compare[int], skiplist.WithSeed(1))
+	for _, k := range []int{50, 10, 40, 20, 30} {
+		m.Put(k, "")
+	}
+
+	k, _, ok := m.First()
+	require.True(t, ok)
+	assert.Equal(t, 10, k)
+	k, _, ok = m.Last()
+	require.True(t, ok)
+	assert.Equal(t, 50, k)
+
+	k, _, ok = m.Floor(35)
+	require.True(t, ok)
+	assert.Equal(t, 30, k)
+	k, _, ok = m.Floor(30)
+	require.True(t, ok)
+	assert.Equal(t, 30, k)
+	_, _, ok = m.Floor(5)
+	assert.False(t, ok)
+
+	k, _, ok = m.Ceiling(35)
+	require.True(t, ok)
+	assert.Equal(t, 40, k)
+	k, _, ok = m.Ceiling(40)
+	require.True(t, ok)
+	assert.Equal(t, 40, k)
+	_, _, ok = m.Ceiling(55)
+	assert.False(t, ok)
+
+	assert.Equal(t, []int{10, 20, 30, 40, 50}, collectKeys(m.All()))
+	assert.Equal(t, []int{50, 40, 30, 20, 10}, collectKeys(m.Backward()))
+	assert.Equal(t, []int{20, 30}, collectKeys(m.Range(15, 40)))
+	assert.Equal(t, []int{40, 50}, collectKeys(m.From(40)))
+	assert.Equal(t, []int{20, 30, 40}, collectKeys(m.RangeByRank(1, 4)))
+	assert.Empty(t, collectKeys(m.RangeByRank(5, 7)))
+}
+
+func TestRankSelect(t *testing.T) {
+	m := skiplist.New[int, int](cmp.Compare[int], skiplist.WithSeed(7))
+	for _, k := range []int{5, 1, 9, 3, 7} {
+		m.Put(k, k*10)
+	}
+
+	rank, ok := m.Rank(7)
+	require.True(t, ok)
+	assert.Equal(t, 3, rank)
+	_, ok = m.Rank(4)
+	assert.False(t, ok)
+
+	k, v, ok := m.Select(1)
+	require.True(t, ok)
+	assert.Equal(t, 3, k)
+	assert.Equal(t, 30, v)
+	_, _, ok = m.Select(5)
+	assert.False(t, ok)
+	_, _, ok = m.Select(-1)
+	assert.False(t, ok)
+}
+
+func TestMaxLevel(t *testing.T) {
+	m := skiplist.New[int, int](cmp.Compare[int], skiplist.WithMaxLevel(1))
+	for i := range 100 {
+		m.Put(99-i, i)
+	}
+	rank, ok := m.Rank(42)
+	require.True(t, ok)
+	assert.Equal(t, 42, rank)
+	k, _, ok := m.Select(42)
+	require.True(t, ok)
+	assert.Equal(t, 42, k)
+}
+
+func TestRandomAgainstSortedSlice(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	m := skiplist.New[int, int](cmp.Compare[int], skiplist.WithSeed(3))
+	want := map[int]int{}
+	for i := range 5000 {
+		k := r.IntN(500)
+		if r.IntN(3) == 0 {
+			_, existed := want[k]
+			assert.Equal(t, existed, m.Delete(k))
+			delete(want, k)
+		} else {
+			_, existed := want[k]
+			assert.Equal(t, !existed, m.Put(k, i))
+			want[k] = i
+		}
+	}
+
+	keys := slices.Sorted(maps.Keys(want))
+	require.Equal(t, len(keys), m.Size())
+	assert.Equal(t, keys, collectKeys(m.All()))
+	for i, k := range keys {
+		rank, ok := m.Rank(k)
+		require.True(t, ok)
+		require.Equal(t, i, rank)
+
+		sk, sv, ok := m.Select(i)
+		require.True(t, ok)
+		require.Equal(t, k, sk)
+		require.Equal(t, want[k], sv)
+	}
+	backward := collectKeys(m.Backward())
+	slices.Reverse(backward)
+	assert.Equal(t, keys, backward)
+}
+
+func collectKeys[K, V any](seq iter.Seq2[K, V]) []K {
+	var keys []K
+	for k := range seq {
+		keys = append(keys, k)
+	}
+	return keys
+}
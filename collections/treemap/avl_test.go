@@ -0,0 +1,56 @@
+package treemap
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// check verifies the order, heights, sizes and balance of the subtree, returning its height and size
+func check[K cmp.Ordered, V any](t *testing.T, n *node[K, V], lo, hi *K) (int, int) {
+	t.Helper()
+	if n == nil {
+		return 0, 0
+	}
+	if lo != nil {
+		require.Greater(t, n.key, *lo)
+	}
+	if hi != nil {
+		require.Less(t, n.key, *hi)
+	}
+	lh, ls := check(t, n.left, lo, &n.key)
+	rh, rs := check(t, n.right, &n.key, hi)
+	require.LessOrEqual(t, lh-rh, 1)
+	require.LessOrEqual(t, rh-lh, 1)
+	require.Equal(t, 1+max(lh, rh), n.height)
+	require.Equal(t, 1+ls+rs, n.size)
+	return n.height, n.size
+}
+
+func TestAVLInvariants(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	m := New[int, int]()
+	for i := range 3000 {
+		k := r.IntN(300)
+		if r.IntN(3) == 0 {
+			m.Delete(k)
+		} else {
+			m.Put(k, i)
+		}
+		if i%100 == 0 {
+			check(t, m.root, nil, nil)
+		}
+	}
+	check(t, m.root, nil, nil)
+
+	// sorted insertions stay balanced
+	m.Clear()
+	for i := range 1 << 12 {
+		m.Put(i, i)
+	}
+	h, s := check(t, m.root, nil, nil)
+	require.Equal(t, 1<<12, s)
+	require.LessOrEqual(t, h, 18)
+}
@@ -0,0 +1,136 @@
+package treemap
+
+import "iter"
+
+// SubMap is a view of the entries of a Map with keys in a range.
+// It reflects the changes to the map, and its changes, like Delete and Clear, are made in the map.
+// New entries are put through the map.
+type SubMap[K, V any] struct {
+	m  *Map[K, V]
+	lo bound[K]
+	hi bound[K]
+}
+
+func (s *SubMap[K, V]) contains(key K) bool {
+	return s.m.afterLower(s.lo, key) && s.m.beforeUpper(s.hi, key)
+}
+
+func (s *SubMap[K, V]) entry(n *node[K, V]) (K, V, bool) {
+	if n == nil || !s.contains(n.key) {
+		return entry[K, V](nil)
+	}
+	return entry(n)
+}
+
+// Size returns the number of entries in the range, O(log n)
+func (s *SubMap[K, V]) Size() int {
+	return max(s.m.within(s.hi)-s.m.below(s.lo), 0)
+}
+
+// Get returns the value of the key, if it is in the range, O(log n)
+func (s *SubMap[K, V]) Get(key K) (V, bool) {
+	if !s.contains(key) {
+		var zero V
+		return zero, false
+	}
+	return s.m.Get(key)
+}
+
+// ContainsKey checks if the key exists in the range, O(log n)
+func (s *SubMap[K, V]) ContainsKey(key K) bool {
+	return s.contains(key) && s.m.ContainsKey(key)
+}
+
+// Delete removes the key from the map, if it is in the range, returning its value, O(log n)
+func (s *SubMap[K, V]) Delete(key K) (V, bool) {
+	if !s.contains(key) {
+		var zero V
+		return zero, false
+	}
+	return s.m.Delete(key)
+}
+
+// Clear removes the entries in the range from the map, O(k log n) for k entries
+func (s *SubMap[K, V]) Clear() {
+	var keys []K
+	for k := range s.Entries() {
+		keys = append(keys, k)
+	}
+	for _, k := range keys {
+		s.m.Delete(k)
+	}
+}
+
+// First returns the entry with the lowest key in the range, O(log n)
+func (s *SubMap[K, V]) First() (K, V, bool) {
+	return s.entry(s.m.first(s.lo))
+}
+
+// Last returns the entry with the highest key in the range, O(log n)
+func (s *SubMap[K, V]) Last() (K, V, bool) {
+	return s.entry(s.m.last(s.hi))
+}
+
+// Floor returns the entry in the range with the greatest key less than or equal to the key, O(log n)
+func (s *SubMap[K, V]) Floor(key K) (K, V, bool) {
+	if !s.m.beforeUpper(s.hi, key) {
+		return s.Last()
+	}
+	return s.entry(s.m.last(inclusive(key)))
+}
+
+// Lower returns the entry in the range with the greatest key strictly less than the key, O(log n)
+func (s *SubMap[K, V]) Lower(key K) (K, V, bool) {
+	if !s.m.beforeUpper(s.hi, key) {
+		return s.Last()
+	}
+	return s.entry(s.m.last(exclusive(key)))
+}
+
+// Ceiling returns the entry in the range with the least key greater than or equal to the key, O(log n)
+func (s *SubMap[K, V]) Ceiling(key K) (K, V, bool) {
+	if !s.m.afterLower(s.lo, key) {
+		return s.First()
+	}
+	return s.entry(s.m.first(inclusive(key)))
+}
+
+// Higher returns the entry in the range with the least key strictly greater than the key, O(log n)
+func (s *SubMap[K, V]) Higher(key K) (K, V, bool) {
+	if !s.m.afterLower(s.lo, key) {
+		return s.First()
+	}
+	return s.entry(s.m.first(exclusive(key)))
+}
+
+// Entries iterates over the entries in the range in ascending order of the keys
+func (s *SubMap[K, V]) Entries() iter.Seq2[K, V] {
+	return s.m.ascend(s.lo, s.hi)
+}
+
+// Keys iterates over the keys in the range in ascending order
+func (s *SubMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range s.Entries() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values iterates over the values in the range in ascending order of the keys
+func (s *SubMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range s.Entries() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// ReverseEntries iterates over the entries in the range in descending order of the keys
+func (s *SubMap[K, V]) ReverseEntries() iter.Seq2[K, V] {
+	return s.m.descend(s.lo, s.hi)
+}
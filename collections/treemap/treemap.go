@@ -0,0 +1,479 @@
+package treemap
+
+import (
+	"cmp"
+	"iter"
+)
+
+type node[K, V any] struct {
+	key         K
+	value       V
+	left, right *node[K, V]
+	height      int
+	// size is the number of entries in the subtree
+	size int
+}
+
+func height[K, V any](n *node[K, V]) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func size[K, V any](n *node[K, V]) int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *node[K, V]) update() {
+	n.height = 1 + max(height(n.left), height(n.right))
+	n.size = 1 + size(n.left) + size(n.right)
+}
+
+func rotateLeft[K, V any](n *node[K, V]) *node[K, V] {
+	r := n.right
+	n.right = r.left
+	r.left = n
+	n.update()
+	r.update()
+	return r
+}
+
+func rotateRight[K, V any](n *node[K, V]) *node[K, V] {
+	l := n.left
+	n.left = l.right
+	l.right = n
+	n.update()
+	l.update()
+	return l
+}
+
+// rebalance restores the AVL property of a node whose subtrees differ at most by 2 in height
+func rebalance[K, V any](n *node[K, V]) *node[K, V] {
+	n.update()
+	switch balance := height(n.left) - height(n.right); {
+	case balance > 1:
+		if height(n.left.left) < height(n.left.right) {
+			n.left = rotateLeft(n.left)
+		}
+		return rotateRight(n)
+	case balance < -1:
+		if height(n.right.right) < height(n.right.left) {
+			n.right = rotateRight(n.right)
+		}
+		return rotateLeft(n)
+	}
+	return n
+}
+
+type Option[K, V any] func(*Map[K, V])
+
+// WithReverse sorts the keys in descending order
+func WithReverse[K, V any]() Option[K, V] {
+	return func(m *Map[K, V]) {
+		m.reverse = true
+	}
+}
+
+// Map is a sorted map backed by an AVL tree.
+// Lookups, insertions and deletions are O(log n), and so are the rank queries, through the subtree sizes.
+// The map must not be changed while iterating over it.
+type Map[K, V any] struct {
+	root    *node[K, V]
+	compare func(a, b K) int
+	reverse bool
+}
+
+// New creates a map sorted by the natural order of the keys
+func New[K cmp.Ordered, V any](options ...Option[K, V]) *Map[K, V] {
+	return NewFunc(cmp.Compare[K], options...)
+}
+
+// NewFunc creates a map sorted by compare, that returns a negative number when a < b, a positive number when a > b and zero when a == b.
+func NewFunc[K, V any](compare func(a, b K) int, options ...Option[K, V]) *Map[K, V] {
+	m := &Map[K, V]{compare: compare}
+	for _, opt := range options {
+		opt(m)
+	}
+	return m
+}
+
+func (m *Map[K, V]) cmp(a, b K) int {
+	if m.reverse {
+		return m.compare(b, a)
+	}
+	return m.compare(a, b)
+}
+
+// Clear removes all entries, O(1)
+func (m *Map[K, V]) Clear() {
+	m.root = nil
+}
+
+// Size returns the number of entries, O(1)
+func (m *Map[K, V]) Size() int {
+	return size(m.root)
+}
+
+// Put sets the value of the key, returning the previous value if the key existed, O(log n)
+func (m *Map[K, V]) Put(key K, value V) (V, bool) {
+	var old V
+	var ok bool
+	m.root = m.put(m.root, key, value, &old, &ok)
+	return old, ok
+}
+
+func (m *Map[K, V]) put(n *node[K, V], key K, value V, old *V, ok *bool) *node[K, V] {
+	if n == nil {
+		return &node[K, V]{key: key, value: value, height: 1, size: 1}
+	}
+	switch c := m.cmp(key, n.key); {
+	case c < 0:
+		n.left = m.put(n.left, key, value, old, ok)
+	case c > 0:
+		n.right = m.put(n.right, key, value, old, ok)
+	default:
+		*old, *ok = n.value, true
+		n.value = value
+		return n
+	}
+	return rebalance(n)
+}
+
+// Get returns the value of the key, O(log n)
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	n := m.find(key)
+	if n == nil {
+		var zero V
+		return zero, false
+	}
+	return n.value, true
+}
+
+// ContainsKey checks if the key exists, O(log n)
+func (m *Map[K, V]) ContainsKey(key K) bool {
+	return m.find(key) != nil
+}
+
+func (m *Map[K, V]) find(key K) *node[K, V] {
+	n := m.root
+	for n != nil {
+		switch c := m.cmp(key, n.key); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+// Delete removes the key, returning its value if it existed, O(log n)
+func (m *Map[K, V]) Delete(key K) (V, bool) {
+	var old V
+	var ok bool
+	m.root = m.delete(m.root, key, &old, &ok)
+	return old, ok
+}
+
+func (m *Map[K, V]) delete(n *node[K, V], key K, old *V, ok *bool) *node[K, V] {
+	if n == nil {
+		return nil
+	}
+	switch c := m.cmp(key, n.key); {
+	case c < 0:
+		n.left = m.delete(n.left, key, old, ok)
+	case c > 0:
+		n.right = m.delete(n.right, key, old, ok)
+	default:
+		*old, *ok = n.value, true
+		if n.left == nil {
+			return n.right
+		}
+		if n.right == nil {
+			return n.left
+		}
+		// the successor takes the place of the node
+		var successor *node[K, V]
+		right := deleteMin(n.right, &successor)
+		successor.left, successor.right = n.left, right
+		n = successor
+	}
+	return rebalance(n)
+}
+
+func deleteMin[K, V any](n *node[K, V], min **node[K, V]) *node[K, V] {
+	if n.left == nil {
+		*min = n
+		return n.right
+	}
+	n.left = deleteMin(n.left, min)
+	return rebalance(n)
+}
+
+// First returns the entry with the lowest key, O(log n)
+func (m *Map[K, V]) First() (K, V, bool) {
+	return entry(m.first(bound[K]{}))
+}
+
+// Last returns the entry with the highest key, O(log n)
+func (m *Map[K, V]) Last() (K, V, bool) {
+	return entry(m.last(bound[K]{}))
+}
+
+// Floor returns the entry with the greatest key less than or equal to the key, O(log n)
+func (m *Map[K, V]) Floor(key K) (K, V, bool) {
+	return entry(m.last(inclusive(key)))
+}
+
+// Lower returns the entry with the greatest key strictly less than the key, O(log n)
+func (m *Map[K, V]) Lower(key K) (K, V, bool) {
+	return entry(m.last(exclusive(key)))
+}
+
+// Ceiling returns the entry with the least key greater than or equal to the key, O(log n)
+func (m *Map[K, V]) Ceiling(key K) (K, V, bool) {
+	return entry(m.first(inclusive(key)))
+}
+
+// Higher returns the entry with the least key strictly greater than the key, O(log n)
+func (m *Map[K, V]) Higher(key K) (K, V, bool) {
+	return entry(m.first(exclusive(key)))
+}
+
+// Rank returns the number of keys less than the key, which is its index if it exists, O(log n)
+func (m *Map[K, V]) Rank(key K) (int, bool) {
+	return m.below(inclusive(key)), m.ContainsKey(key)
+}
+
+// Select returns the entry at the index in the sorted order, O(log n)
+func (m *Map[K, V]) Select(index int) (K, V, bool) {
+	if index < 0 || index >= m.Size() {
+		return entry[K, V](nil)
+	}
+	n := m.root
+	for {
+		s := size(n.left)
+		switch {
+		case index < s:
+			n = n.left
+		case index > s:
+			index -= s + 1
+			n = n.right
+		default:
+			return entry(n)
+		}
+	}
+}
+
+// Entries iterates over all entries in ascending order of the keys
+func (m *Map[K, V]) Entries() iter.Seq2[K, V] {
+	return m.ascend(bound[K]{}, bound[K]{})
+}
+
+// Keys iterates over all keys in ascending order
+func (m *Map[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range m.Entries() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values iterates over all values in ascending order of the keys
+func (m *Map[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.Entries() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// ReverseEntries iterates over all entries in descending order of the keys
+func (m *Map[K, V]) ReverseEntries() iter.Seq2[K, V] {
+	return m.descend(bound[K]{}, bound[K]{})
+}
+
+// Range iterates in ascending order over the entries with keys between from, inclusive, and to, exclusive
+func (m *Map[K, V]) Range(from, to K) iter.Seq2[K, V] {
+	return m.ascend(inclusive(from), exclusive(to))
+}
+
+// ReverseRange iterates in descending order over the entries with keys between from, inclusive, and to, exclusive
+func (m *Map[K, V]) ReverseRange(from, to K) iter.Seq2[K, V] {
+	return m.descend(inclusive(from), exclusive(to))
+}
+
+// SubMap returns a view of the entries with keys between from, inclusive, and to, exclusive
+func (m *Map[K, V]) SubMap(from, to K) *SubMap[K, V] {
+	return &SubMap[K, V]{m: m, lo: inclusive(from), hi: exclusive(to)}
+}
+
+// HeadMap returns a view of the entries with keys less than to
+func (m *Map[K, V]) HeadMap(to K) *SubMap[K, V] {
+	return &SubMap[K, V]{m: m, hi: exclusive(to)}
+}
+
+// TailMap returns a view of the entries with keys greater than or equal to from
+func (m *Map[K, V]) TailMap(from K) *SubMap[K, V] {
+	return &SubMap[K, V]{m: m, lo: inclusive(from)}
+}
+
+// bound limits a range of keys. The zero value is unbounded.
+type bound[K any] struct {
+	key       K
+	set       bool
+	inclusive bool
+}
+
+func inclusive[K any](key K) bound[K] {
+	return bound[K]{key: key, set: true, inclusive: true}
+}
+
+func exclusive[K any](key K) bound[K] {
+	return bound[K]{key: key, set: true}
+}
+
+// afterLower checks if the key is within the lower bound
+func (m *Map[K, V]) afterLower(lo bound[K], key K) bool {
+	if !lo.set {
+		return true
+	}
+	c := m.cmp(key, lo.key)
+	return c > 0 || (c == 0 && lo.inclusive)
+}
+
+// beforeUpper checks if the key is within the upper bound
+func (m *Map[K, V]) beforeUpper(hi bound[K], key K) bool {
+	if !hi.set {
+		return true
+	}
+	c := m.cmp(key, hi.key)
+	return c < 0 || (c == 0 && hi.inclusive)
+}
+
+// first returns the node with the least key within the lower bound
+func (m *Map[K, V]) first(lo bound[K]) *node[K, V] {
+	var found *node[K, V]
+	for n := m.root; n != nil; {
+		if m.afterLower(lo, n.key) {
+			found = n
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return found
+}
+
+// last returns the node with the greatest key within the upper bound
+func (m *Map[K, V]) last(hi bound[K]) *node[K, V] {
+	var found *node[K, V]
+	for n := m.root; n != nil; {
+		if m.beforeUpper(hi, n.key) {
+			found = n
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return found
+}
+
+// below returns the number of keys before the lower bound
+func (m *Map[K, V]) below(lo bound[K]) int {
+	count := 0
+	for n := m.root; n != nil; {
+		if m.afterLower(lo, n.key) {
+			n = n.left
+		} else {
+			count += size(n.left) + 1
+			n = n.right
+		}
+	}
+	return count
+}
+
+// within returns the number of keys within the upper bound
+func (m *Map[K, V]) within(hi bound[K]) int {
+	count := 0
+	for n := m.root; n != nil; {
+		if m.beforeUpper(hi, n.key) {
+			count += size(n.left) + 1
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return count
+}
+
+// ascend iterates in ascending order over the entries between the bounds
+func (m *Map[K, V]) ascend(lo, hi bound[K]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		// the stack holds the nodes after the lower bound, the next to visit at the top
+		var stack []*node[K, V]
+		for n := m.root; n != nil; {
+			if m.afterLower(lo, n.key) {
+				stack = append(stack, n)
+				n = n.left
+			} else {
+				n = n.right
+			}
+		}
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if !m.beforeUpper(hi, n.key) || !yield(n.key, n.value) {
+				return
+			}
+			for c := n.right; c != nil; c = c.left {
+				stack = append(stack, c)
+			}
+		}
+	}
+}
+
+// descend iterates in descending order over the entries between the bounds
+func (m *Map[K, V]) descend(lo, hi bound[K]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		// the stack holds the nodes before the upper bound, the next to visit at the top
+		var stack []*node[K, V]
+		for n := m.root; n != nil; {
+			if m.beforeUpper(hi, n.key) {
+				stack = append(stack, n)
+				n = n.right
+			} else {
+				n = n.left
+			}
+		}
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if !m.afterLower(lo, n.key) || !yield(n.key, n.value) {
+				return
+			}
+			for c := n.left; c != nil; c = c.right {
+				stack = append(stack, c)
+			}
+		}
+	}
+}
+
+func entry[K, V any](n *node[K, V]) (K, V, bool) {
+	if n == nil {
+		var k K
+		var v V
+		return k, v, false
+	}
+	return n.key, n.value, true
+}
@@ -0,0 +1,304 @@
+package treemap_test
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quintans/ds/collections/treemap"
+)
+
+func TestPutGetDelete(t *testing.T) {
+	m := treemap.New[string, int]()
+	_, ok := m.Put("b", 2)
+	assert.False(t, ok)
+	m.Put("a", 1)
+	m.Put("c", 3)
+	old, ok := m.Put("b", 20)
+	assert.True(t, ok)
+	assert.Equal(t, 2, old)
+	assert.Equal(t, 3, m.Size())
+
+	v, ok := m.Get("b")
+	require.True(t, ok)
+	assert.Equal(t, 20, v)
+	_, ok = m.Get("d")
+	assert.False(t, ok)
+	assert.True(t, m.ContainsKey("a"))
+
+	old, ok = m.Delete("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, old)
+	_, ok = m.Delete("a")
+	assert.False(t, ok)
+	assert.Equal(t, 2, m.Size())
+	assert.Equal(t, []string{"b", "c"}, slices.Collect(m.Keys()))
+	assert.Equal(t, []int{20, 3}, slices.Collect(m.Values()))
+
+	m.Clear()
+	assert.Equal(t, 0, m.Size())
+	_, _, ok = m.First()
+	assert.False(t, ok)
+}
+
+func TestNavigation(t *testing.T) {
+	m := treemap.New[int, string]()
+	for _, k := range []int{50, 10, 40, 20, 30} {
+		m.Put(k, "")
+	}
+
+	key := func(k int, _ string, ok bool) any {
+		if !ok {
+			return nil
+		}
+		return k
+	}
+	assert.Equal(t, 10, key(m.First()))
+	assert.Equal(t, 50, key(m.Last()))
+
+	assert.Equal(t, 30, key(m.Floor(35)))
+	assert.Equal(t, 30, key(m.Floor(30)))
+	assert.Nil(t, key(m.Floor(5)))
+	assert.Equal(t, 20, key(m.Lower(30)))
+	assert.Nil(t, key(m.Lower(10)))
+
+	assert.Equal(t, 40, key(m.Ceiling(35)))
+	assert.Equal(t, 30, key(m.Ceiling(30)))
+	assert.Nil(t, key(m.Ceiling(55)))
+	assert.Equal(t, 40, key(m.Higher(30)))
+	assert.Nil(t, key(m.Higher(50)))
+}
+
+func TestRankSelect(t *testing.T) {
+	m := treemap.New[int, int]()
+	for k := range 100 {
+		m.Put(k*2, k)
+	}
+
+	rank, ok := m.Rank(10)
+	assert.True(t, ok)
+	assert.Equal(t, 5, rank)
+	rank, ok = m.Rank(11)
+	assert.False(t, ok)
+	assert.Equal(t, 6, rank)
+	rank, _ = m.Rank(-1)
+	assert.Equal(t, 0, rank)
+	rank, _ = m.Rank(1000)
+	assert.Equal(t, 100, rank)
+
+	for i := range 100 {
+		k, v, ok := m.Select(i)
+		require.True(t, ok)
+		assert.Equal(t, i*2, k)
+		assert.Equal(t, i, v)
+	}
+	_, _, ok = m.Select(100)
+	assert.False(t, ok)
+	_, _, ok = m.Select(-1)
+	assert.False(t, ok)
+}
+
+func TestIteration(t *testing.T) {
+	m := treemap.New[int, int]()
+	for _, k := range rand.Perm(20) {
+		m.Put(k, k*10)
+	}
+
+	var keys []int
+	for k, v := range m.Entries() {
+		assert.Equal(t, k*10, v)
+		keys = append(keys, k)
+	}
+	assert.Equal(t, rangeOf(0, 20), keys)
+
+	keys = keys[:0]
+	for k := range m.ReverseEntries() {
+		keys = append(keys, k)
+	}
+	assert.Equal(t, reversed(rangeOf(0, 20)), keys)
+
+	assert.Equal(t, rangeOf(5, 12), collectKeys(m.Range(5, 12)))
+	assert.Equal(t, reversed(rangeOf(5, 12)), collectKeys(m.ReverseRange(5, 12)))
+	assert.Empty(t, collectKeys(m.Range(12, 5)))
+	assert.Equal(t, rangeOf(15, 20), collectKeys(m.Range(15, 100)))
+
+	// stops early
+	for range m.Range(0, 20) {
+		break
+	}
+	for range m.ReverseRange(0, 20) {
+		break
+	}
+}
+
+func TestSubMap(t *testing.T) {
+	m := treemap.New[int, string]()
+	for k := 0; k < 100; k += 10 {
+		m.Put(k, "")
+	}
+
+	s := m.SubMap(25, 70)
+	assert.Equal(t, 4, s.Size())
+	assert.Equal(t, []int{30, 40, 50, 60}, slices.Collect(s.Keys()))
+	assert.Equal(t, []int{60, 50, 40, 30}, collectKeys(s.ReverseEntries()))
+
+	key := func(k int, _ string, ok bool) any {
+		if !ok {
+			return nil
+		}
+		return k
+	}
+	assert.Equal(t, 30, key(s.First()))
+	assert.Equal(t, 60, key(s.Last()))
+	assert.Equal(t, 60, key(s.Floor(95)))
+	assert.Nil(t, key(s.Floor(25)))
+	assert.Equal(t, 40, key(s.Lower(50)))
+	assert.Equal(t, 60, key(s.Lower(70)))
+	assert.Equal(t, 30, key(s.Ceiling(0)))
+	assert.Nil(t, key(s.Ceiling(65)))
+	assert.Equal(t, 60, key(s.Higher(50)))
+	assert.Nil(t, key(s.Higher(60)))
+
+	assert.True(t, s.ContainsKey(30))
+	assert.False(t, s.ContainsKey(20))
+	_, ok := s.Get(70)
+	assert.False(t, ok)
+	_, ok = s.Delete(80)
+	assert.False(t, ok)
+
+	// the view reflects the map and the other way around
+	m.Put(35, "")
+	assert.Equal(t, 5, s.Size())
+	_, ok = s.Delete(40)
+	assert.True(t, ok)
+	assert.False(t, m.ContainsKey(40))
+
+	s.Clear()
+	assert.Equal(t, 0, s.Size())
+	assert.Equal(t, []int{0, 10, 20, 70, 80, 90}, slices.Collect(m.Keys()))
+
+	assert.Equal(t, []int{0, 10}, slices.Collect(m.HeadMap(20).Keys()))
+	assert.Equal(t, []int{80, 90}, slices.Collect(m.TailMap(75).Keys()))
+	assert.Equal(t, 0, m.SubMap(50, 10).Size())
+}
+
+func TestComparator(t *testing.T) {
+	m := treemap.NewFunc[string, int](func(a, b string) int {
+		return cmp.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	m.Put("b", 1)
+	m.Put("A", 2)
+	m.Put("a", 3)
+	assert.Equal(t, []string{"A", "b"}, slices.Collect(m.Keys()))
+	v, _ := m.Get("a")
+	assert.Equal(t, 3, v)
+
+	r := treemap.New(treemap.WithReverse[int, string]())
+	for _, k := range []int{2, 3, 1} {
+		r.Put(k, "")
+	}
+	assert.Equal(t, []int{3, 2, 1}, slices.Collect(r.Keys()))
+	assert.Equal(t, []int{3, 2}, collectKeys(r.Range(3, 1)))
+	// 5 comes before all keys in descending order
+	_, _, ok := r.Floor(5)
+	assert.False(t, ok)
+	k, _, _ := r.Ceiling(5)
+	assert.Equal(t, 3, k)
+}
+
+func TestTimeSeries(t *testing.T) {
+	m := treemap.NewFunc[time.Time, float64](time.Time.Compare)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 24 {
+		m.Put(start.Add(time.Duration(i)*time.Hour), float64(i))
+	}
+
+	var sum float64
+	for _, v := range m.Range(start.Add(6*time.Hour), start.Add(12*time.Hour)) {
+		sum += v
+	}
+	assert.Equal(t, float64(6+7+8+9+10+11), sum)
+
+	// drop what is older than 20h
+	m.HeadMap(start.Add(20 * time.Hour)).Clear()
+	assert.Equal(t, 4, m.Size())
+	k, _, _ := m.First()
+	assert.Equal(t, start.Add(20*time.Hour), k)
+}
+
+func TestRandomOperations(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	m := treemap.New[int, int]()
+	model := map[int]int{}
+	for range 5000 {
+		k := r.IntN(500)
+		switch r.IntN(3) {
+		case 0, 1:
+			old, ok := m.Put(k, k+1)
+			mold, mok := model[k]
+			require.Equal(t, mok, ok)
+			require.Equal(t, mold, old)
+			model[k] = k + 1
+		case 2:
+			old, ok := m.Delete(k)
+			mold, mok := model[k]
+			require.Equal(t, mok, ok)
+			require.Equal(t, mold, old)
+			delete(model, k)
+		}
+	}
+
+	keys := slices.Sorted(func(yield func(int) bool) {
+		for k := range model {
+			if !yield(k) {
+				return
+			}
+		}
+	})
+	require.Equal(t, len(keys), m.Size())
+	require.Equal(t, keys, slices.Collect(m.Keys()))
+
+	for range 200 {
+		from, to := r.IntN(520)-10, r.IntN(520)-10
+		var want []int
+		for _, k := range keys {
+			if k >= from && k < to {
+				want = append(want, k)
+			}
+		}
+		require.Equal(t, want, collectKeys(m.Range(from, to)))
+		require.Equal(t, len(want), m.SubMap(from, to).Size())
+
+		idx, _ := slices.BinarySearch(keys, from)
+		rank, _ := m.Rank(from)
+		require.Equal(t, idx, rank)
+	}
+}
+
+func collectKeys[K, V any](seq func(yield func(K, V) bool)) []K {
+	var keys []K
+	for k := range seq {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func rangeOf(from, to int) []int {
+	var r []int
+	for i := from; i < to; i++ {
+		r = append(r, i)
+	}
+	return r
+}
+
+func reversed(s []int) []int {
+	s = slices.Clone(s)
+	slices.Reverse(s)
+	return s
+}
@@ -0,0 +1,243 @@
+package unrolledlist_test
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quintans/ds/collections/linkedlist"
+	"github.com/quintans/ds/collections/unrolledlist"
+)
+
+func TestNew(t *testing.T) {
+	l := unrolledlist.New[int]()
+	assert.Equal(t, 0, l.Size())
+	_, err := l.PeekFirst()
+	assert.Error(t, err)
+	_, err = l.PeekLast()
+	assert.Error(t, err)
+	_, err = l.RemoveFirst()
+	assert.Error(t, err)
+	_, err = l.RemoveLast()
+	assert.Error(t, err)
+	_, err = l.Get(0)
+	assert.Error(t, err)
+}
+
+func TestAddGetSet(t *testing.T) {
+	l := unrolledlist.New[int]()
+	for i := range 200 {
+		l.Add(i)
+	}
+	for i := range 100 {
+		l.AddFirst(-i - 1)
+	}
+	assert.Equal(t, 300, l.Size())
+
+	for i := range 300 {
+		v, err := l.Get(i)
+		require.NoError(t, err)
+		assert.Equal(t, i-100, v)
+	}
+
+	require.NoError(t, l.Set(150, 1000))
+	v, err := l.Get(150)
+	require.NoError(t, err)
+	assert.Equal(t, 1000, v)
+	assert.Error(t, l.Set(300, 0))
+	assert.Error(t, l.Set(-1, 0))
+
+	first, err := l.PeekFirst()
+	require.NoError(t, err)
+	assert.Equal(t, -100, first)
+	last, err := l.PeekLast()
+	require.NoError(t, err)
+	assert.Equal(t, 199, last)
+}
+
+func TestAddAtDeleteAt(t *testing.T) {
+	l := unrolledlist.New[string]()
+	require.NoError(t, l.AddAt(0, "b"))
+	require.NoError(t, l.AddAt(0, "a"))
+	require.NoError(t, l.AddAt(2, "d"))
+	require.NoError(t, l.AddAt(2, "c"))
+	assert.Error(t, l.AddAt(5, "x"))
+	assert.Equal(t, []string{"a", "b", "c", "d"}, slices.Collect(l.Values()))
+
+	v, err := l.DeleteAt(1)
+	require.NoError(t, err)
+	assert.Equal(t, "b", v)
+	_, err = l.DeleteAt(3)
+	assert.Error(t, err)
+
+	v, err = l.RemoveFirst()
+	require.NoError(t, err)
+	assert.Equal(t, "a", v)
+	v, err = l.RemoveLast()
+	require.NoError(t, err)
+	assert.Equal(t, "d", v)
+	assert.Equal(t, []string{"c"}, slices.Collect(l.Values()))
+}
+
+func TestRandomAgainstSlice(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	l := unrolledlist.New[int]()
+	var want []int
+	for i := range 20000 {
+		switch op := r.IntN(10); {
+		case op < 4:
+			index := r.IntN(len(want) + 1)
+			require.NoError(t, l.AddAt(index, i))
+			want = slices.Insert(want, index, i)
+		case op < 5:
+			l.Add(i)
+			want = append(want, i)
+		case op < 6:
+			l.AddFirst(i)
+			want = slices.Insert(want, 0, i)
+		case len(want) == 0:
+		case op < 9:
+			index := r.IntN(len(want))
+			v, err := l.DeleteAt(index)
+			require.NoError(t, err)
+			require.Equal(t, want[index], v)
+			want = slices.Delete(want, index, index+1)
+		default:
+			index := r.IntN(len(want))
+			v, err := l.Get(index)
+			require.NoError(t, err)
+			require.Equal(t, want[index], v)
+		}
+		require.Equal(t, len(want), l.Size())
+	}
+	assert.Equal(t, want, slices.Collect(l.Values()))
+}
+
+func TestEntries(t *testing.T) {
+	l := unrolledlist.New[string]()
+	l.Add("a")
+	l.Add("b")
+	var indexes []int
+	for i, v := range l.Entries() {
+		indexes = append(indexes, i)
+		assert.Equal(t, string(rune('a'+i)), v)
+	}
+	assert.Equal(t, []int{0, 1}, indexes)
+}
+
+func TestClearClone(t *testing.T) {
+	l := unrolledlist.New[int]()
+	for i := range 100 {
+		l.Add(i)
+	}
+	c := l.Clone()
+	l.Clear()
+	assert.Equal(t, 0, l.Size())
+	assert.Empty(t, slices.Collect(l.Values()))
+
+	assert.Equal(t, 100, c.Size())
+	require.NoError(t, c.Set(0, -1))
+	v, err := c.Get(99)
+	require.NoError(t, err)
+	assert.Equal(t, 99, v)
+}
+
+const benchSize = 100_000
+
+func BenchmarkAdd(b *testing.B) {
+	b.Run("unrolledlist", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			l := unrolledlist.New[int]()
+			for i := range benchSize {
+				l.Add(i)
+			}
+		}
+	})
+	b.Run("linkedlist", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			l := linkedlist.New[int]()
+			for i := range benchSize {
+				l.Add(i)
+			}
+		}
+	})
+}
+
+func BenchmarkGet(b *testing.B) {
+	ul := unrolledlist.New[int]()
+	ll := linkedlist.New[int]()
+	for i := range benchSize {
+		ul.Add(i)
+		ll.Add(i)
+	}
+	r := rand.New(rand.NewPCG(1, 2))
+
+	b.Run("unrolledlist", func(b *testing.B) {
+		for b.Loop() {
+			ul.Get(r.IntN(benchSize))
+		}
+	})
+	b.Run("linkedlist", func(b *testing.B) {
+		for b.Loop() {
+			ll.Get(r.IntN(benchSize))
+		}
+	})
+}
+
+func BenchmarkAddAt(b *testing.B) {
+	r := rand.New(rand.NewPCG(1, 2))
+	b.Run("unrolledlist", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			l := unrolledlist.New[int]()
+			for i := range 10_000 {
+				l.AddAt(r.IntN(i+1), i)
+			}
+		}
+	})
+	b.Run("linkedlist", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			l := linkedlist.New[int]()
+			for i := range 10_000 {
+				// linkedlist does not add at the size index
+				if index := r.IntN(i + 1); index == i {
+					l.Add(i)
+				} else {
+					l.AddAt(index, i)
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkValues(b *testing.B) {
+	ul := unrolledlist.New[int]()
+	ll := linkedlist.New[int]()
+	for i := range benchSize {
+		ul.Add(i)
+		ll.Add(i)
+	}
+
+	b.Run("unrolledlist", func(b *testing.B) {
+		for b.Loop() {
+			sum := 0
+			for v := range ul.Values() {
+				sum += v
+			}
+		}
+	})
+	b.Run("linkedlist", func(b *testing.B) {
+		for b.Loop() {
+			sum := 0
+			for v := range ll.Values() {
+				sum += v
+			}
+		}
+	})
+}
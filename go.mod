@@ -5,6 +5,7 @@ go 1.25
 require (
 	github.com/quintans/faults v1.7.1
 	github.com/stretchr/testify v1.7.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -12,5 +13,4 @@ require (
 	github.com/kr/pretty v0.2.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
-	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
 )
@@ -0,0 +1,447 @@
+package resp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errSyntax      = errors.New("ERR syntax error")
+	errNotInteger  = errors.New("ERR value is not an integer or out of range")
+	errNotFloat    = errors.New("ERR value is not a valid float")
+	errNoSuchKey   = errors.New("ERR no such key")
+	errOutOfRange  = errors.New("ERR index out of range")
+	errOverflow    = errors.New("ERR increment or decrement would overflow")
+	errNotPositive = errors.New("ERR value is out of range, must be positive")
+)
+
+func errWrongArgs(name string) error {
+	return fmt.Errorf("ERR wrong number of arguments for '%s' command", strings.ToLower(name))
+}
+
+func errUnknownCommand(name string) error {
+	return fmt.Errorf("ERR unknown command '%s'", name)
+}
+
+type command struct {
+	// arity follows the Redis convention: a positive value is the exact number of arguments,
+	// including the command name, and a negative value is the minimum number of arguments.
+	arity   int
+	handler func(s *Server, c *client, args []string) error
+}
+
+var commands = map[string]command{
+	// connection
+	"PING":    {-1, cmdPing},
+	"ECHO":    {2, cmdEcho},
+	"HELLO":   {-1, cmdHello},
+	"SELECT":  {2, cmdSelect},
+	"QUIT":    {-1, cmdQuit},
+	"CLIENT":  {-2, cmdClient},
+	"COMMAND": {-1, cmdCommand},
+	"INFO":    {-1, cmdInfo},
+	// keys
+	"FLUSHDB":   {-1, cmdFlush},
+	"FLUSHALL":  {-1, cmdFlush},
+	"DBSIZE":    {1, cmdDBSize},
+	"DEL":       {-2, cmdDel},
+	"UNLINK":    {-2, cmdDel},
+	"EXISTS":    {-2, cmdExists},
+	"TYPE":      {2, cmdType},
+	"KEYS":      {2, cmdKeys},
+	"RENAME":    {3, cmdRename},
+	"EXPIRE":    {3, cmdExpire},
+	"PEXPIRE":   {3, cmdPExpire},
+	"EXPIREAT":  {3, cmdExpireAt},
+	"PEXPIREAT": {3, cmdPExpireAt},
+	"TTL":       {2, cmdTTL},
+	"PTTL":      {2, cmdPTTL},
+	"PERSIST":   {2, cmdPersist},
+	// strings
+	"GET":         {2, cmdGet},
+	"SET":         {-3, cmdSet},
+	"SETNX":       {3, cmdSetNX},
+	"SETEX":       {4, cmdSetEX},
+	"PSETEX":      {4, cmdPSetEX},
+	"GETDEL":      {2, cmdGetDel},
+	"MGET":        {-2, cmdMGet},
+	"MSET":        {-3, cmdMSet},
+	"INCR":        {2, cmdIncr},
+	"DECR":        {2, cmdDecr},
+	"INCRBY":      {3, cmdIncrBy},
+	"DECRBY":      {3, cmdDecrBy},
+	"INCRBYFLOAT": {3, cmdIncrByFloat},
+	"APPEND":      {3, cmdAppend},
+	"STRLEN":      {2, cmdStrLen},
+	// lists
+	"LPUSH":  {-3, cmdLPush},
+	"RPUSH":  {-3, cmdRPush},
+	"LPOP":   {-2, cmdLPop},
+	"RPOP":   {-2, cmdRPop},
+	"LLEN":   {2, cmdLLen},
+	"LRANGE": {4, cmdLRange},
+	"LINDEX": {3, cmdLIndex},
+	"LSET":   {4, cmdLSet},
+	"LREM":   {4, cmdLRem},
+	"LTRIM":  {4, cmdLTrim},
+	// hashes
+	"HSET":    {-4, cmdHSet},
+	"HMSET":   {-4, cmdHMSet},
+	"HSETNX":  {4, cmdHSetNX},
+	"HGET":    {3, cmdHGet},
+	"HMGET":   {-3, cmdHMGet},
+	"HDEL":    {-3, cmdHDel},
+	"HEXISTS": {3, cmdHExists},
+	"HLEN":    {2, cmdHLen},
+	"HKEYS":   {2, cmdHKeys},
+	"HVALS":   {2, cmdHVals},
+	"HGETALL": {2, cmdHGetAll},
+	"HINCRBY": {4, cmdHIncrBy},
+	// sets
+	"SADD":      {-3, cmdSAdd},
+	"SREM":      {-3, cmdSRem},
+	"SMEMBERS":  {2, cmdSMembers},
+	"SISMEMBER": {3, cmdSIsMember},
+	"SCARD":     {2, cmdSCard},
+	"SPOP":      {-2, cmdSPop},
+	"SINTER":    {-2, cmdSInter},
+	"SUNION":    {-2, cmdSUnion},
+	"SDIFF":     {-2, cmdSDiff},
+	// sorted sets
+	"ZADD":          {-4, cmdZAdd},
+	"ZINCRBY":       {4, cmdZIncrBy},
+	"ZSCORE":        {3, cmdZScore},
+	"ZREM":          {-3, cmdZRem},
+	"ZCARD":         {2, cmdZCard},
+	"ZCOUNT":        {4, cmdZCount},
+	"ZRANK":         {3, cmdZRank},
+	"ZREVRANK":      {3, cmdZRevRank},
+	"ZRANGE":        {-4, cmdZRange},
+	"ZREVRANGE":     {-4, cmdZRevRange},
+	"ZRANGEBYSCORE": {-4, cmdZRangeByScore},
+	"ZPOPMIN":       {-2, cmdZPopMin},
+	"ZPOPMAX":       {-2, cmdZPopMax},
+}
+
+func parseInt(s string) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, errNotInteger
+	}
+	return n, nil
+}
+
+// parseDuration converts n units into a duration, failing for the command if it overflows
+func parseDuration(cmd string, n int64, unit time.Duration) (time.Duration, error) {
+	if n > math.MaxInt64/int64(unit) || n < math.MinInt64/int64(unit) {
+		return 0, errInvalidExpireTime(cmd)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+func errInvalidExpireTime(cmd string) error {
+	return errors.New("ERR invalid expire time in '" + strings.ToLower(cmd) + "' command")
+}
+
+func parseFloat(s string) (float64, error) {
+	switch strings.ToLower(s) {
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) {
+		return 0, errNotFloat
+	}
+	return f, nil
+}
+
+// normalizeRange converts a Redis inclusive range, where negative indexes count from the end,
+// into a half open range [from, to). ok is false if the range is empty.
+func normalizeRange(start, stop int64, size int) (from, to int, ok bool) {
+	n := int64(size)
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	start = max(start, 0)
+	stop = min(stop, n-1)
+	if start > stop || start >= n {
+		return 0, 0, false
+	}
+	return int(start), int(stop) + 1, true
+}
+
+// connection commands
+
+func cmdPing(s *Server, c *client, args []string) error {
+	switch len(args) {
+	case 1:
+		c.w.simple("PONG")
+	case 2:
+		c.w.bulk(args[1])
+	default:
+		return errWrongArgs(args[0])
+	}
+	return nil
+}
+
+func cmdEcho(s *Server, c *client, args []string) error {
+	c.w.bulk(args[1])
+	return nil
+}
+
+func cmdHello(s *Server, c *client, args []string) error {
+	proto := c.w.proto
+	if len(args) > 1 {
+		p, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errors.New("ERR Protocol version is not an integer or out of range")
+		}
+		if p != 2 && p != 3 {
+			return errors.New("NOPROTO unsupported protocol version")
+		}
+		proto = p
+
+		// authentication and client names are accepted and ignored
+		for i := 2; i < len(args); i++ {
+			switch strings.ToUpper(args[i]) {
+			case "AUTH":
+				i += 2
+			case "SETNAME":
+				i++
+			default:
+				return errSyntax
+			}
+			if i >= len(args) {
+				return errSyntax
+			}
+		}
+	}
+	c.w.proto = proto
+
+	c.w.mapHeader(7)
+	c.w.bulk("server")
+	c.w.bulk("redis")
+	c.w.bulk("version")
+	c.w.bulk("7.2.0")
+	c.w.bulk("proto")
+	c.w.integer(int64(proto))
+	c.w.bulk("id")
+	c.w.integer(c.id)
+	c.w.bulk("mode")
+	c.w.bulk("standalone")
+	c.w.bulk("role")
+	c.w.bulk("master")
+	c.w.bulk("modules")
+	c.w.array(0)
+	return nil
+}
+
+func cmdSelect(s *Server, c *client, args []string) error {
+	if args[1] != "0" {
+		return errors.New("ERR DB index is out of range")
+	}
+	c.w.ok()
+	return nil
+}
+
+func cmdQuit(s *Server, c *client, args []string) error {
+	c.w.ok()
+	c.quit = true
+	return nil
+}
+
+func cmdClient(s *Server, c *client, args []string) error {
+	switch strings.ToUpper(args[1]) {
+	case "ID":
+		c.w.integer(c.id)
+	case "GETNAME":
+		c.w.null()
+	case "SETNAME", "SETINFO":
+		c.w.ok()
+	default:
+		return fmt.Errorf("ERR unknown subcommand '%s'", args[1])
+	}
+	return nil
+}
+
+func cmdCommand(s *Server, c *client, args []string) error {
+	// command introspection is not supported, but clients call it on connect
+	c.w.array(0)
+	return nil
+}
+
+func cmdInfo(s *Server, c *client, args []string) error {
+	c.w.bulk(fmt.Sprintf("# Server\r\nredis_version:7.2.0\r\nredis_mode:standalone\r\n\r\n# Keyspace\r\ndb0:keys=%d\r\n", s.ks.size()))
+	return nil
+}
+
+// key commands
+
+func cmdFlush(s *Server, c *client, args []string) error {
+	s.ks.flush()
+	c.w.ok()
+	return nil
+}
+
+func cmdDBSize(s *Server, c *client, args []string) error {
+	c.w.integer(int64(s.ks.size()))
+	return nil
+}
+
+func cmdDel(s *Server, c *client, args []string) error {
+	var count int64
+	for _, key := range args[1:] {
+		if s.ks.delete(key) {
+			count++
+		}
+	}
+	c.w.integer(count)
+	return nil
+}
+
+func cmdExists(s *Server, c *client, args []string) error {
+	var count int64
+	for _, key := range args[1:] {
+		if _, ok := s.ks.get(key); ok {
+			count++
+		}
+	}
+	c.w.integer(count)
+	return nil
+}
+
+func cmdType(s *Server, c *client, args []string) error {
+	e, ok := s.ks.get(args[1])
+	if !ok {
+		c.w.simple("none")
+		return nil
+	}
+	c.w.simple(e.typeName())
+	return nil
+}
+
+func cmdKeys(s *Server, c *client, args []string) error {
+	var keys []string
+	for _, key := range s.ks.keys() {
+		if matchPattern(args[1], key) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	c.w.bulks(keys)
+	return nil
+}
+
+func cmdRename(s *Server, c *client, args []string) error {
+	e, ok := s.ks.get(args[1])
+	if !ok {
+		return errNoSuchKey
+	}
+	s.ks.entries.Delete(args[1])
+	s.ks.entries.Put(args[2], e)
+	c.w.ok()
+	return nil
+}
+
+func expire(s *Server, c *client, key string, at time.Time) {
+	e, ok := s.ks.get(key)
+	if !ok {
+		c.w.integer(0)
+		return
+	}
+	if !at.After(s.now()) {
+		s.ks.delete(key)
+	} else {
+		e.expireAt = at
+	}
+	c.w.integer(1)
+}
+
+func cmdExpire(s *Server, c *client, args []string) error {
+	n, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	d, err := parseDuration(args[0], n, time.Second)
+	if err != nil {
+		return err
+	}
+	expire(s, c, args[1], s.now().Add(d))
+	return nil
+}
+
+func cmdPExpire(s *Server, c *client, args []string) error {
+	n, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	d, err := parseDuration(args[0], n, time.Millisecond)
+	if err != nil {
+		return err
+	}
+	expire(s, c, args[1], s.now().Add(d))
+	return nil
+}
+
+func cmdExpireAt(s *Server, c *client, args []string) error {
+	n, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	expire(s, c, args[1], time.Unix(n, 0))
+	return nil
+}
+
+func cmdPExpireAt(s *Server, c *client, args []string) error {
+	n, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	expire(s, c, args[1], time.UnixMilli(n))
+	return nil
+}
+
+// ttl returns the remaining time to live of a key, -1 if it has no TTL and -2 if it does not exist
+func ttl(s *Server, key string, unit time.Duration) int64 {
+	e, ok := s.ks.get(key)
+	if !ok {
+		return -2
+	}
+	if !e.hasTTL() {
+		return -1
+	}
+	remaining := e.expireAt.Sub(s.now())
+	// round to the nearest unit, like Redis
+	return int64((remaining + unit/2) / unit)
+}
+
+func cmdTTL(s *Server, c *client, args []string) error {
+	c.w.integer(ttl(s, args[1], time.Second))
+	return nil
+}
+
+func cmdPTTL(s *Server, c *client, args []string) error {
+	c.w.integer(ttl(s, args[1], time.Millisecond))
+	return nil
+}
+
+func cmdPersist(s *Server, c *client, args []string) error {
+	e, ok := s.ks.get(args[1])
+	if !ok || !e.hasTTL() {
+		c.w.integer(0)
+		return nil
+	}
+	e.expireAt = time.Time{}
+	c.w.integer(1)
+	return nil
+}
@@ -0,0 +1,82 @@
+package resp
+
+// matchPattern reports whether s matches a Redis glob style pattern.
+// It supports '*', '?', character classes like [abc], [a-z] and [^a], and '\' to escape special characters.
+func matchPattern(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if matchPattern(pattern[1:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+			s = s[1:]
+			pattern = pattern[1:]
+		case '[':
+			if len(s) == 0 {
+				return false
+			}
+			matched, rest, ok := matchClass(pattern[1:], s[0])
+			if !ok || !matched {
+				return false
+			}
+			pattern = rest
+			s = s[1:]
+		case '\\':
+			if len(pattern) >= 2 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(s) == 0 || pattern[0] != s[0] {
+				return false
+			}
+			s = s[1:]
+			pattern = pattern[1:]
+		}
+	}
+	return len(s) == 0
+}
+
+// matchClass matches c against the character class at the start of pattern, just after the '['.
+// It returns the pattern after the closing ']', and ok is false if the class is not terminated.
+func matchClass(pattern string, c byte) (matched bool, rest string, ok bool) {
+	negate := false
+	if len(pattern) > 0 && pattern[0] == '^' {
+		negate = true
+		pattern = pattern[1:]
+	}
+	for len(pattern) > 0 && pattern[0] != ']' {
+		switch {
+		case pattern[0] == '\\' && len(pattern) >= 2:
+			matched = matched || pattern[1] == c
+			pattern = pattern[2:]
+		case len(pattern) >= 3 && pattern[1] == '-' && pattern[2] != ']':
+			lo, hi := pattern[0], pattern[2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			matched = matched || (c >= lo && c <= hi)
+			pattern = pattern[3:]
+		default:
+			matched = matched || pattern[0] == c
+			pattern = pattern[1:]
+		}
+	}
+	if len(pattern) == 0 {
+		return false, "", false
+	}
+	return matched != negate, pattern[1:], true
+}
@@ -0,0 +1,36 @@
+package resp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		match   bool
+	}{
+		{"*", "", true},
+		{"*", "anything", true},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h*llo", "heeeello", true},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-b]llo", "hbllo", true},
+		{"h[a-b]llo", "hcllo", false},
+		{`h\*llo`, "h*llo", true},
+		{`h\*llo`, "hello", false},
+		{"user:*:name", "user:1:name", true},
+		{"user:*:name", "user:1:age", false},
+		{"[abc", "a", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.match, matchPattern(tt.pattern, tt.s), "pattern %q against %q", tt.pattern, tt.s)
+	}
+}
@@ -0,0 +1,223 @@
+package resp
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/quintans/ds/collections/linkedmap"
+)
+
+var errHashNotInteger = errors.New("ERR hash value is not an integer")
+
+func newHash() *linkedmap.Map[string, string] {
+	return linkedmap.New[string, string]()
+}
+
+func hset(s *Server, args []string) (int64, error) {
+	if len(args)%2 != 0 {
+		return 0, errWrongArgs(args[0])
+	}
+	h, err := lookupOrCreate(s.ks, args[1], newHash)
+	if err != nil {
+		return 0, err
+	}
+	var added int64
+	for i := 2; i < len(args); i += 2 {
+		if _, ok := h.Put(args[i], args[i+1]); !ok {
+			added++
+		}
+	}
+	return added, nil
+}
+
+func cmdHSet(s *Server, c *client, args []string) error {
+	added, err := hset(s, args)
+	if err != nil {
+		return err
+	}
+	c.w.integer(added)
+	return nil
+}
+
+func cmdHMSet(s *Server, c *client, args []string) error {
+	if _, err := hset(s, args); err != nil {
+		return err
+	}
+	c.w.ok()
+	return nil
+}
+
+func cmdHSetNX(s *Server, c *client, args []string) error {
+	h, err := lookupOrCreate(s.ks, args[1], newHash)
+	if err != nil {
+		return err
+	}
+	if h.ContainsKey(args[2]) {
+		c.w.integer(0)
+		return nil
+	}
+	h.Put(args[2], args[3])
+	c.w.integer(1)
+	return nil
+}
+
+func cmdHGet(s *Server, c *client, args []string) error {
+	h, ok, err := lookup[*linkedmap.Map[string, string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.null()
+		return nil
+	}
+	v, ok := h.Get(args[2])
+	if !ok {
+		c.w.null()
+		return nil
+	}
+	c.w.bulk(v)
+	return nil
+}
+
+func cmdHMGet(s *Server, c *client, args []string) error {
+	h, ok, err := lookup[*linkedmap.Map[string, string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	c.w.array(len(args) - 2)
+	for _, field := range args[2:] {
+		if !ok {
+			c.w.null()
+			continue
+		}
+		v, found := h.Get(field)
+		if !found {
+			c.w.null()
+			continue
+		}
+		c.w.bulk(v)
+	}
+	return nil
+}
+
+func cmdHDel(s *Server, c *client, args []string) error {
+	h, ok, err := lookup[*linkedmap.Map[string, string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.integer(0)
+		return nil
+	}
+	var removed int64
+	for _, field := range args[2:] {
+		if _, ok := h.Delete(field); ok {
+			removed++
+		}
+	}
+	s.ks.deleteIfEmpty(args[1], h.Size())
+	c.w.integer(removed)
+	return nil
+}
+
+func cmdHExists(s *Server, c *client, args []string) error {
+	h, ok, err := lookup[*linkedmap.Map[string, string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if ok && h.ContainsKey(args[2]) {
+		c.w.integer(1)
+	} else {
+		c.w.integer(0)
+	}
+	return nil
+}
+
+func cmdHLen(s *Server, c *client, args []string) error {
+	h, ok, err := lookup[*linkedmap.Map[string, string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.integer(0)
+		return nil
+	}
+	c.w.integer(int64(h.Size()))
+	return nil
+}
+
+func cmdHKeys(s *Server, c *client, args []string) error {
+	h, ok, err := lookup[*linkedmap.Map[string, string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.array(0)
+		return nil
+	}
+	c.w.array(h.Size())
+	for k := range h.Keys() {
+		c.w.bulk(k)
+	}
+	return nil
+}
+
+func cmdHVals(s *Server, c *client, args []string) error {
+	h, ok, err := lookup[*linkedmap.Map[string, string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.array(0)
+		return nil
+	}
+	c.w.array(h.Size())
+	for v := range h.Values() {
+		c.w.bulk(v)
+	}
+	return nil
+}
+
+func cmdHGetAll(s *Server, c *client, args []string) error {
+	h, ok, err := lookup[*linkedmap.Map[string, string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.mapHeader(0)
+		return nil
+	}
+	c.w.mapHeader(h.Size())
+	for k, v := range h.Entries() {
+		c.w.bulk(k)
+		c.w.bulk(v)
+	}
+	return nil
+}
+
+func cmdHIncrBy(s *Server, c *client, args []string) error {
+	delta, err := parseInt(args[3])
+	if err != nil {
+		return err
+	}
+	h, err := lookupOrCreate(s.ks, args[1], newHash)
+	if err != nil {
+		return err
+	}
+	var n int64
+	if old, ok := h.Get(args[2]); ok {
+		n, err = strconv.ParseInt(old, 10, 64)
+		if err != nil {
+			return errHashNotInteger
+		}
+	}
+	if (delta > 0 && n > math.MaxInt64-delta) || (delta < 0 && n < math.MinInt64-delta) {
+		s.ks.deleteIfEmpty(args[1], h.Size())
+		return errOverflow
+	}
+	n += delta
+	h.Put(args[2], strconv.FormatInt(n, 10))
+	c.w.integer(n)
+	return nil
+}
@@ -0,0 +1,151 @@
+package resp
+
+import (
+	"errors"
+	"time"
+
+	"github.com/quintans/ds/cache"
+	"github.com/quintans/ds/collections/linkedlist"
+	"github.com/quintans/ds/collections/linkedmap"
+	"github.com/quintans/ds/collections/set"
+)
+
+var errWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+
+// entry is a value stored in the keyspace.
+// data holds one of string, *linkedlist.List[string], *set.Set[string, string], *linkedmap.Map[string, string] or *zset
+type entry struct {
+	data     any
+	expireAt time.Time
+}
+
+func (e *entry) hasTTL() bool {
+	return !e.expireAt.IsZero()
+}
+
+// typeName returns the name of the type of the value, as reported by the TYPE command
+func (e *entry) typeName() string {
+	switch e.data.(type) {
+	case string:
+		return "string"
+	case *linkedlist.List[string]:
+		return "list"
+	case *set.Set[string, string]:
+		return "set"
+	case *linkedmap.Map[string, string]:
+		return "hash"
+	case *zset:
+		return "zset"
+	default:
+		return "none"
+	}
+}
+
+// keyspace holds all the keys of the database.
+// Keys are kept in a LRU cache so that, when bounded, the least recently used keys are evicted first.
+// Keys with a TTL are expired lazily, when they are accessed.
+type keyspace struct {
+	entries *cache.LRU[string, *entry]
+	now     func() time.Time
+}
+
+func newKeyspace(maxKeys int, now func() time.Time) *keyspace {
+	return &keyspace{
+		entries: cache.NewLRU[string, *entry](maxKeys, nil),
+		now:     now,
+	}
+}
+
+func (k *keyspace) get(key string) (*entry, bool) {
+	e, ok := k.entries.Get(key)
+	if !ok {
+		return nil, false
+	}
+	if k.expired(e) {
+		k.entries.Delete(key)
+		return nil, false
+	}
+	return e, true
+}
+
+func (k *keyspace) expired(e *entry) bool {
+	return e.hasTTL() && !e.expireAt.After(k.now())
+}
+
+// put stores the data under key, discarding any previous value and TTL
+func (k *keyspace) put(key string, data any) *entry {
+	e := &entry{data: data}
+	k.entries.Put(key, e)
+	return e
+}
+
+func (k *keyspace) delete(key string) bool {
+	if _, ok := k.get(key); !ok {
+		return false
+	}
+	k.entries.Delete(key)
+	return true
+}
+
+// deleteIfEmpty removes a container key once its last element is gone
+func (k *keyspace) deleteIfEmpty(key string, size int) {
+	if size == 0 {
+		k.entries.Delete(key)
+	}
+}
+
+// purge removes all expired keys
+func (k *keyspace) purge() {
+	var expired []string
+	for key, e := range k.entries.Iterator() {
+		if k.expired(e) {
+			expired = append(expired, key)
+		}
+	}
+	for _, key := range expired {
+		k.entries.Delete(key)
+	}
+}
+
+func (k *keyspace) keys() []string {
+	k.purge()
+	keys := make([]string, 0, k.entries.Size())
+	for key := range k.entries.Iterator() {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (k *keyspace) size() int {
+	k.purge()
+	return k.entries.Size()
+}
+
+func (k *keyspace) flush() {
+	k.entries.Clear()
+}
+
+// lookup returns the value stored at key if it has the expected type.
+func lookup[T any](k *keyspace, key string) (T, bool, error) {
+	var zero T
+	e, ok := k.get(key)
+	if !ok {
+		return zero, false, nil
+	}
+	v, ok := e.data.(T)
+	if !ok {
+		return zero, false, errWrongType
+	}
+	return v, true, nil
+}
+
+// lookupOrCreate returns the value stored at key, creating it if it does not exist.
+func lookupOrCreate[T any](k *keyspace, key string, create func() T) (T, error) {
+	v, ok, err := lookup[T](k, key)
+	if err != nil || ok {
+		return v, err
+	}
+	v = create()
+	k.put(key, v)
+	return v, nil
+}
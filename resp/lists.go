@@ -0,0 +1,268 @@
+package resp
+
+import (
+	"github.com/quintans/ds/collections/linkedlist"
+)
+
+func newList() *linkedlist.List[string] {
+	return linkedlist.New[string]()
+}
+
+func cmdLPush(s *Server, c *client, args []string) error {
+	l, err := lookupOrCreate(s.ks, args[1], newList)
+	if err != nil {
+		return err
+	}
+	for _, v := range args[2:] {
+		l.AddFirst(v)
+	}
+	c.w.integer(int64(l.Size()))
+	return nil
+}
+
+func cmdRPush(s *Server, c *client, args []string) error {
+	l, err := lookupOrCreate(s.ks, args[1], newList)
+	if err != nil {
+		return err
+	}
+	for _, v := range args[2:] {
+		l.Add(v)
+	}
+	c.w.integer(int64(l.Size()))
+	return nil
+}
+
+// pop implements LPOP and RPOP key [count]
+func pop(s *Server, c *client, args []string, remove func(l *linkedlist.List[string]) (string, error)) error {
+	if len(args) > 3 {
+		return errSyntax
+	}
+	count := int64(-1)
+	if len(args) == 3 {
+		n, err := parseInt(args[2])
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return errNotPositive
+		}
+		count = n
+	}
+
+	l, ok, err := lookup[*linkedlist.List[string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if count < 0 {
+			c.w.null()
+		} else {
+			c.w.nullArray()
+		}
+		return nil
+	}
+
+	if count < 0 {
+		v, _ := remove(l)
+		s.ks.deleteIfEmpty(args[1], l.Size())
+		c.w.bulk(v)
+		return nil
+	}
+
+	values := make([]string, 0, min(int(count), l.Size()))
+	for range min(int(count), l.Size()) {
+		v, _ := remove(l)
+		values = append(values, v)
+	}
+	s.ks.deleteIfEmpty(args[1], l.Size())
+	c.w.bulks(values)
+	return nil
+}
+
+func cmdLPop(s *Server, c *client, args []string) error {
+	return pop(s, c, args, (*linkedlist.List[string]).RemoveFirst)
+}
+
+func cmdRPop(s *Server, c *client, args []string) error {
+	return pop(s, c, args, (*linkedlist.List[string]).RemoveLast)
+}
+
+func cmdLLen(s *Server, c *client, args []string) error {
+	l, ok, err := lookup[*linkedlist.List[string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.integer(0)
+		return nil
+	}
+	c.w.integer(int64(l.Size()))
+	return nil
+}
+
+func cmdLRange(s *Server, c *client, args []string) error {
+	start, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	stop, err := parseInt(args[3])
+	if err != nil {
+		return err
+	}
+	l, ok, err := lookup[*linkedlist.List[string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.array(0)
+		return nil
+	}
+
+	from, to, ok := normalizeRange(start, stop, l.Size())
+	if !ok {
+		c.w.array(0)
+		return nil
+	}
+	values := make([]string, 0, to-from)
+	for i, v := range l.Entries() {
+		if i >= to {
+			break
+		}
+		if i >= from {
+			values = append(values, v)
+		}
+	}
+	c.w.bulks(values)
+	return nil
+}
+
+func listIndex(l *linkedlist.List[string], arg string) (int, error) {
+	i, err := parseInt(arg)
+	if err != nil {
+		return 0, err
+	}
+	if i < 0 {
+		i += int64(l.Size())
+	}
+	return int(i), nil
+}
+
+func cmdLIndex(s *Server, c *client, args []string) error {
+	l, ok, err := lookup[*linkedlist.List[string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.null()
+		return nil
+	}
+	i, err := listIndex(l, args[2])
+	if err != nil {
+		return err
+	}
+	v, err := l.Get(i)
+	if err != nil {
+		c.w.null()
+		return nil
+	}
+	c.w.bulk(v)
+	return nil
+}
+
+func cmdLSet(s *Server, c *client, args []string) error {
+	l, ok, err := lookup[*linkedlist.List[string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errNoSuchKey
+	}
+	i, err := listIndex(l, args[2])
+	if err != nil {
+		return err
+	}
+	if err := l.Set(i, args[3]); err != nil {
+		return errOutOfRange
+	}
+	c.w.ok()
+	return nil
+}
+
+// cmdLRem implements LREM key count element.
+// A positive count removes from head to tail, a negative one from tail to head and zero removes all occurrences.
+func cmdLRem(s *Server, c *client, args []string) error {
+	count, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	l, ok, err := lookup[*linkedlist.List[string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.integer(0)
+		return nil
+	}
+
+	limit := count
+	if limit < 0 {
+		limit = -limit
+	}
+	var removed int64
+	if count >= 0 {
+		for e := l.Head(); e != nil && (limit == 0 || removed < limit); {
+			next := e.Next()
+			if e.Value() == args[3] {
+				e.Remove()
+				removed++
+			}
+			e = next
+		}
+	} else {
+		for e := l.Tail(); e != nil && removed < limit; {
+			prev := e.Previous()
+			if e.Value() == args[3] {
+				e.Remove()
+				removed++
+			}
+			e = prev
+		}
+	}
+	s.ks.deleteIfEmpty(args[1], l.Size())
+	c.w.integer(removed)
+	return nil
+}
+
+func cmdLTrim(s *Server, c *client, args []string) error {
+	start, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	stop, err := parseInt(args[3])
+	if err != nil {
+		return err
+	}
+	l, ok, err := lookup[*linkedlist.List[string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.ok()
+		return nil
+	}
+
+	from, to, ok := normalizeRange(start, stop, l.Size())
+	if !ok {
+		s.ks.delete(args[1])
+		c.w.ok()
+		return nil
+	}
+	for range l.Size() - to {
+		l.RemoveLast()
+	}
+	for range from {
+		l.RemoveFirst()
+	}
+	c.w.ok()
+	return nil
+}
@@ -0,0 +1,213 @@
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxBulkLen is the largest bulk string accepted from a client, the same as Redis' proto-max-bulk-len
+	maxBulkLen = 512 * 1024 * 1024
+	// maxArrayLen is the largest number of arguments accepted in a single command
+	maxArrayLen = 1024 * 1024
+	// maxInlineLen is the longest line accepted, be it an inline command or a header, like Redis' PROTO_INLINE_MAX_SIZE
+	maxInlineLen = 64 * 1024
+	// bulkChunk is how much of a bulk string is allocated ahead of the data actually received
+	bulkChunk = 64 * 1024
+)
+
+var errProtocol = errors.New("ERR Protocol error")
+
+// readCommand reads the next command sent by a client.
+// Commands are either RESP arrays of bulk strings or inline commands separated by spaces.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, nil
+	}
+
+	if line[0] != '*' {
+		return strings.Fields(line), nil
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n > maxArrayLen {
+		return nil, fmt.Errorf("%w: invalid multibulk length", errProtocol)
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+
+	// the arguments are allocated as they arrive, so a large count alone does not allocate
+	args := make([]string, 0, min(n, 1024))
+	for range n {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, fmt.Errorf("%w: expected '$', got '%s'", errProtocol, line)
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 || size > maxBulkLen {
+			return nil, fmt.Errorf("%w: invalid bulk length", errProtocol)
+		}
+		arg, err := readBulk(r, size)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// readBulk reads a bulk string of the given size followed by CRLF.
+// Large strings are allocated in chunks as the data is received, so a header alone cannot allocate up to maxBulkLen.
+func readBulk(r *bufio.Reader, size int) (string, error) {
+	buf := make([]byte, 0, min(size, bulkChunk)+2)
+	for len(buf) < size+2 {
+		n := min(size+2-len(buf), bulkChunk)
+		buf = slices.Grow(buf, n)
+		if _, err := io.ReadFull(r, buf[len(buf):len(buf)+n]); err != nil {
+			return "", err
+		}
+		buf = buf[:len(buf)+n]
+	}
+	if buf[size] != '\r' || buf[size+1] != '\n' {
+		return "", fmt.Errorf("%w: bulk string not terminated by CRLF", errProtocol)
+	}
+	return string(buf[:size]), nil
+}
+
+// readLine reads a line terminated by LF, failing if it is longer than maxInlineLen
+func readLine(r *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if len(line)+len(chunk) > maxInlineLen {
+			return "", fmt.Errorf("%w: too big inline request", errProtocol)
+		}
+		line = append(line, chunk...)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return "", err
+		}
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+// writer encodes replies using the protocol version negotiated by the client.
+// RESP3 types are downgraded to their RESP2 equivalents when the client did not switch protocols.
+type writer struct {
+	w     *bufio.Writer
+	proto int
+}
+
+func newWriter(w io.Writer) *writer {
+	return &writer{
+		w:     bufio.NewWriter(w),
+		proto: 2,
+	}
+}
+
+func (w *writer) flush() error {
+	return w.w.Flush()
+}
+
+func (w *writer) simple(s string) {
+	w.w.WriteString("+" + s + "\r\n")
+}
+
+func (w *writer) ok() {
+	w.simple("OK")
+}
+
+// error writes an error reply. The message is expected to start with an error code, like "ERR".
+func (w *writer) error(err error) {
+	w.w.WriteString("-" + strings.ReplaceAll(err.Error(), "\r\n", " ") + "\r\n")
+}
+
+func (w *writer) integer(n int64) {
+	w.w.WriteString(":" + strconv.FormatInt(n, 10) + "\r\n")
+}
+
+func (w *writer) bulk(s string) {
+	w.w.WriteString("$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n")
+}
+
+func (w *writer) null() {
+	if w.proto == 3 {
+		w.w.WriteString("_\r\n")
+		return
+	}
+	w.w.WriteString("$-1\r\n")
+}
+
+func (w *writer) nullArray() {
+	if w.proto == 3 {
+		w.w.WriteString("_\r\n")
+		return
+	}
+	w.w.WriteString("*-1\r\n")
+}
+
+func (w *writer) double(f float64) {
+	if w.proto == 3 {
+		w.w.WriteString("," + formatFloat(f) + "\r\n")
+		return
+	}
+	w.bulk(formatFloat(f))
+}
+
+func (w *writer) array(n int) {
+	w.w.WriteString("*" + strconv.Itoa(n) + "\r\n")
+}
+
+// mapHeader starts a map with n key/value pairs.
+// In RESP2 it becomes a flat array of 2*n elements.
+func (w *writer) mapHeader(n int) {
+	if w.proto == 3 {
+		w.w.WriteString("%" + strconv.Itoa(n) + "\r\n")
+		return
+	}
+	w.array(n * 2)
+}
+
+// setHeader starts a set with n elements.
+// In RESP2 it becomes an array.
+func (w *writer) setHeader(n int) {
+	if w.proto == 3 {
+		w.w.WriteString("~" + strconv.Itoa(n) + "\r\n")
+		return
+	}
+	w.array(n)
+}
+
+func (w *writer) bulks(values []string) {
+	w.array(len(values))
+	for _, v := range values {
+		w.bulk(v)
+	}
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
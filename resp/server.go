@@ -0,0 +1,207 @@
+// Package resp implements a small subset of a Redis server, speaking RESP2 and RESP3,
+// where the data is held in the collections of this module.
+// It is intended to be embedded as a dependency free stand-in for Redis in integration tests.
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrServerClosed is returned by Serve and ListenAndServe after a call to Close.
+var ErrServerClosed = errors.New("resp: Server closed")
+
+type Option func(*Server)
+
+// WithMaxKeys bounds the number of keys in the database.
+// When the limit is reached the least recently used key is evicted, like Redis' allkeys-lru policy.
+// Zero, the default, means unbounded.
+func WithMaxKeys(maxKeys int) Option {
+	return func(s *Server) {
+		s.maxKeys = maxKeys
+	}
+}
+
+// WithClock replaces the clock used to compute key expirations.
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+type Server struct {
+	mu       sync.Mutex
+	ks       *keyspace
+	maxKeys  int
+	now      func() time.Time
+	nextID   int64
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+type client struct {
+	id   int64
+	r    *bufio.Reader
+	w    *writer
+	quit bool
+}
+
+func New(options ...Option) *Server {
+	s := &Server{
+		now:   time.Now,
+		conns: map[net.Conn]struct{}{},
+	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	s.ks = newKeyspace(s.maxKeys, s.now)
+	return s
+}
+
+// ListenAndServe listens on the TCP network address addr and then calls Serve.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts connections on the listener, serving each one in its own goroutine.
+// It blocks until the listener fails or the server is closed, in which case it returns ErrServerClosed.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	s.listener = l
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return ErrServerClosed
+		}
+		s.conns[conn] = struct{}{}
+		s.nextID++
+		id := s.nextID
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.handle(conn, id)
+	}
+}
+
+// Addr returns the address the server is listening on, or nil if it is not serving.
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Close stops the listener, closes all client connections and waits for their goroutines to finish.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) handle(conn net.Conn, id int64) {
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		s.wg.Done()
+	}()
+
+	c := &client{
+		id: id,
+		r:  bufio.NewReader(conn),
+		w:  newWriter(conn),
+	}
+
+	for !c.quit {
+		args, err := readCommand(c.r)
+		if err != nil {
+			if errors.Is(err, errProtocol) {
+				c.w.error(err)
+				c.w.flush()
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		s.dispatch(c, args)
+
+		// only flush when there are no more pipelined commands
+		if c.quit || c.r.Buffered() == 0 {
+			if err := c.w.flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) dispatch(c *client, args []string) {
+	name := strings.ToUpper(args[0])
+	cmd, ok := commands[name]
+	if !ok {
+		c.w.error(errUnknownCommand(args[0]))
+		return
+	}
+	if (cmd.arity > 0 && len(args) != cmd.arity) || (cmd.arity < 0 && len(args) < -cmd.arity) {
+		c.w.error(errWrongArgs(name))
+		return
+	}
+
+	s.mu.Lock()
+	err := cmd.handler(s, c, args)
+	s.mu.Unlock()
+
+	if err != nil {
+		c.w.error(err)
+	}
+}
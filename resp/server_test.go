@@ -0,0 +1,483 @@
+package resp_test
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quintans/ds/resp"
+)
+
+type respError string
+
+func (e respError) Error() string {
+	return string(e)
+}
+
+// testClient is a minimal RESP client, decoding replies into Go values
+type testClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// startServer serves on a random loopback port and returns its address
+func startServer(t *testing.T, options ...resp.Option) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := resp.New(options...)
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(l)
+	}()
+	t.Cleanup(func() {
+		require.NoError(t, srv.Close())
+		require.ErrorIs(t, <-done, resp.ErrServerClosed)
+	})
+	return l.Addr().String()
+}
+
+func connect(t *testing.T, addr string) *testClient {
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+	})
+	return &testClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+func (c *testClient) send(args ...string) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&sb, "$%d\r\n%s\r\n", len(a), a)
+	}
+	_, err := c.conn.Write([]byte(sb.String()))
+	require.NoError(c.t, err)
+}
+
+func (c *testClient) do(args ...string) any {
+	c.send(args...)
+	return c.read()
+}
+
+func (c *testClient) read() any {
+	v, err := readReply(c.r)
+	require.NoError(c.t, err)
+	return v
+}
+
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	payload := line[1:]
+	switch line[0] {
+	case '+':
+		return payload, nil
+	case '-':
+		return respError(payload), nil
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case ',':
+		return strconv.ParseFloat(strings.Replace(payload, "inf", "Inf", 1), 64)
+	case '#':
+		return payload == "t", nil
+	case '_':
+		return nil, nil
+	case '$':
+		n, _ := strconv.Atoi(payload)
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*', '~':
+		n, _ := strconv.Atoi(payload)
+		if n < 0 {
+			return nil, nil
+		}
+		values := make([]any, 0, n)
+		for range n {
+			v, err := readReply(r)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	case '%':
+		n, _ := strconv.Atoi(payload)
+		m := make(map[string]any, n)
+		for range n {
+			k, err := readReply(r)
+			if err != nil {
+				return nil, err
+			}
+			v, err := readReply(r)
+			if err != nil {
+				return nil, err
+			}
+			m[fmt.Sprint(k)] = v
+		}
+		return m, nil
+	default:
+		return nil, errors.New("unknown reply type: " + line)
+	}
+}
+
+func list(values ...any) []any {
+	return values
+}
+
+func TestConnection(t *testing.T) {
+	c := connect(t, startServer(t))
+
+	assert.Equal(t, "PONG", c.do("PING"))
+	assert.Equal(t, "hello", c.do("PING", "hello"))
+	assert.Equal(t, "hello", c.do("echo", "hello"))
+	assert.Equal(t, "OK", c.do("SELECT", "0"))
+	assert.Equal(t, respError("ERR unknown command 'NOPE'"), c.do("NOPE"))
+	assert.Equal(t, respError("ERR wrong number of arguments for 'get' command"), c.do("GET"))
+
+	// inline commands
+	_, err := c.conn.Write([]byte("PING\r\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "PONG", c.read())
+
+	// pipelining
+	c.send("SET", "a", "1")
+	c.send("INCR", "a")
+	c.send("GET", "a")
+	assert.Equal(t, "OK", c.read())
+	assert.Equal(t, int64(2), c.read())
+	assert.Equal(t, "2", c.read())
+
+	assert.Equal(t, "OK", c.do("QUIT"))
+	_, err = c.r.ReadByte()
+	assert.Error(t, err, "connection should be closed after QUIT")
+}
+
+func TestProtocolLimits(t *testing.T) {
+	addr := startServer(t)
+
+	tests := []struct {
+		name  string
+		input string
+		err   respError
+	}{
+		{"inline", strings.Repeat("a", 64*1024) + "\r\n", "ERR Protocol error: too big inline request"},
+		{"header", "*1\r\n$" + strings.Repeat("1", 64*1024) + "\r\n", "ERR Protocol error: too big inline request"},
+		{"bulk length", "*1\r\n$536870913\r\n", "ERR Protocol error: invalid bulk length"},
+		{"array length", "*1048577\r\n", "ERR Protocol error: invalid multibulk length"},
+		{"unterminated", "*1\r\n$4\r\nPINGxx", "ERR Protocol error: bulk string not terminated by CRLF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := connect(t, addr)
+			_, err := c.conn.Write([]byte(tt.input))
+			require.NoError(t, err)
+			assert.Equal(t, tt.err, c.read())
+			_, err = c.r.ReadByte()
+			assert.Error(t, err, "connection should be closed after a protocol error")
+		})
+	}
+
+	// a bulk string larger than the allocation chunk is still read whole
+	c := connect(t, addr)
+	big := strings.Repeat("x", 200*1024)
+	assert.Equal(t, "OK", c.do("SET", "big", big))
+	assert.Equal(t, big, c.do("GET", "big"))
+}
+
+func TestStrings(t *testing.T) {
+	c := connect(t, startServer(t))
+
+	assert.Nil(t, c.do("GET", "k"))
+	assert.Equal(t, "OK", c.do("SET", "k", "v"))
+	assert.Equal(t, "v", c.do("GET", "k"))
+	assert.Nil(t, c.do("SET", "k", "x", "NX"))
+	assert.Equal(t, "v", c.do("SET", "k", "w", "GET"))
+	assert.Nil(t, c.do("SET", "other", "x", "XX"))
+	assert.Equal(t, int64(0), c.do("SETNX", "k", "z"))
+	assert.Equal(t, int64(4), c.do("APPEND", "k", "abc"))
+	assert.Equal(t, int64(4), c.do("STRLEN", "k"))
+
+	assert.Equal(t, "OK", c.do("MSET", "a", "1", "b", "2"))
+	assert.Equal(t, list("1", "2", nil), c.do("MGET", "a", "b", "c"))
+	assert.Equal(t, int64(11), c.do("INCRBY", "a", "10"))
+	assert.Equal(t, int64(10), c.do("DECR", "a"))
+	assert.Equal(t, "10.5", c.do("INCRBYFLOAT", "a", "0.5"))
+	assert.Equal(t, respError("ERR value is not an integer or out of range"), c.do("INCR", "a"))
+
+	assert.Equal(t, "OK", c.do("SET", "empty", ""))
+	assert.Equal(t, respError("ERR value is not an integer or out of range"), c.do("INCR", "empty"))
+	assert.Equal(t, respError("ERR value is not an integer or out of range"), c.do("INCRBY", "empty", "5"))
+	assert.Equal(t, respError("ERR value is not a valid float"), c.do("INCRBYFLOAT", "empty", "1.5"))
+	assert.Equal(t, "", c.do("GET", "empty"))
+	assert.Equal(t, int64(1), c.do("INCR", "counter"))
+
+	assert.Equal(t, "2", c.do("GETDEL", "b"))
+	assert.Equal(t, int64(0), c.do("EXISTS", "b"))
+
+	c.do("RPUSH", "l", "x")
+	assert.Equal(t, respError("WRONGTYPE Operation against a key holding the wrong kind of value"), c.do("GET", "l"))
+	assert.Equal(t, "list", c.do("TYPE", "l"))
+	assert.Equal(t, "string", c.do("TYPE", "k"))
+	assert.Equal(t, "none", c.do("TYPE", "missing"))
+}
+
+func TestKeys(t *testing.T) {
+	c := connect(t, startServer(t))
+
+	c.do("MSET", "user:1", "a", "user:2", "b", "order:1", "c")
+	assert.Equal(t, list("user:1", "user:2"), c.do("KEYS", "user:*"))
+	assert.Equal(t, list("order:1", "user:1"), c.do("KEYS", "*[:]1"))
+	assert.Equal(t, int64(3), c.do("DBSIZE"))
+	assert.Equal(t, int64(2), c.do("DEL", "user:1", "user:2", "user:3"))
+
+	assert.Equal(t, "OK", c.do("RENAME", "order:1", "order:2"))
+	assert.Equal(t, "c", c.do("GET", "order:2"))
+	assert.Equal(t, respError("ERR no such key"), c.do("RENAME", "order:1", "order:3"))
+
+	assert.Equal(t, "OK", c.do("FLUSHDB"))
+	assert.Equal(t, int64(0), c.do("DBSIZE"))
+}
+
+func TestTTL(t *testing.T) {
+	var mu sync.Mutex
+	now := time.Unix(1_000_000, 0)
+	clock := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		now = now.Add(d)
+		mu.Unlock()
+	}
+	c := connect(t, startServer(t, resp.WithClock(clock)))
+
+	assert.Equal(t, "OK", c.do("SET", "k", "v", "EX", "10"))
+	assert.Equal(t, int64(10), c.do("TTL", "k"))
+	assert.Equal(t, int64(10_000), c.do("PTTL", "k"))
+	assert.Equal(t, int64(-2), c.do("TTL", "missing"))
+
+	// KEEPTTL retains the expiration and a plain SET clears it
+	c.do("SET", "k", "w", "KEEPTTL")
+	assert.Equal(t, int64(10), c.do("TTL", "k"))
+	c.do("SET", "k", "v")
+	assert.Equal(t, int64(-1), c.do("TTL", "k"))
+
+	assert.Equal(t, int64(1), c.do("EXPIRE", "k", "5"))
+	assert.Equal(t, int64(0), c.do("EXPIRE", "missing", "5"))
+	advance(4 * time.Second)
+	assert.Equal(t, "v", c.do("GET", "k"))
+	advance(time.Second)
+	assert.Nil(t, c.do("GET", "k"))
+
+	c.do("SETEX", "s", "1", "v")
+	c.do("PSETEX", "p", "1500", "v")
+	c.do("RPUSH", "l", "a")
+	c.do("PEXPIRE", "l", "1000")
+	assert.Equal(t, int64(3), c.do("DBSIZE"))
+	advance(time.Second)
+	assert.Equal(t, list("p"), c.do("KEYS", "*"))
+
+	assert.Equal(t, int64(1), c.do("PERSIST", "p"))
+	assert.Equal(t, int64(0), c.do("PERSIST", "p"))
+	advance(time.Hour)
+	assert.Equal(t, "v", c.do("GET", "p"))
+
+	// incrementing keeps the TTL
+	c.do("SET", "n", "1", "PX", "2000")
+	c.do("INCR", "n")
+	assert.Equal(t, int64(2000), c.do("PTTL", "n"))
+
+	// durations that do not fit are rejected instead of overflowing
+	huge := "9223372036854775"
+	assert.Equal(t, respError("ERR invalid expire time in 'expire' command"), c.do("EXPIRE", "n", huge))
+	assert.Equal(t, respError("ERR invalid expire time in 'expire' command"), c.do("EXPIRE", "n", "-"+huge))
+	assert.Equal(t, respError("ERR invalid expire time in 'pexpire' command"), c.do("PEXPIRE", "n", "9223372036854775807"))
+	assert.Equal(t, respError("ERR invalid expire time in 'set' command"), c.do("SET", "n", "v", "EX", huge))
+	assert.Equal(t, respError("ERR invalid expire time in 'set' command"), c.do("SET", "n", "v", "PX", "9223372036854775807"))
+	assert.Equal(t, respError("ERR invalid expire time in 'setex' command"), c.do("SETEX", "n", huge, "v"))
+	assert.Equal(t, int64(2000), c.do("PTTL", "n"))
+}
+
+func TestLists(t *testing.T) {
+	c := connect(t, startServer(t))
+
+	assert.Equal(t, int64(3), c.do("RPUSH", "l", "b", "c", "d"))
+	assert.Equal(t, int64(5), c.do("LPUSH", "l", "a", "z"))
+	assert.Equal(t, list("z", "a", "b", "c", "d"), c.do("LRANGE", "l", "0", "-1"))
+	assert.Equal(t, list("c", "d"), c.do("LRANGE", "l", "-2", "100"))
+	assert.Equal(t, []any{}, c.do("LRANGE", "l", "3", "1"))
+	assert.Equal(t, "d", c.do("LINDEX", "l", "-1"))
+	assert.Nil(t, c.do("LINDEX", "l", "10"))
+	assert.Equal(t, "OK", c.do("LSET", "l", "0", "y"))
+	assert.Equal(t, respError("ERR index out of range"), c.do("LSET", "l", "9", "y"))
+
+	assert.Equal(t, "y", c.do("LPOP", "l"))
+	assert.Equal(t, list("d", "c"), c.do("RPOP", "l", "2"))
+	assert.Equal(t, int64(2), c.do("LLEN", "l"))
+
+	c.do("RPUSH", "r", "x", "a", "x", "b", "x")
+	assert.Equal(t, int64(2), c.do("LREM", "r", "-2", "x"))
+	assert.Equal(t, list("x", "a", "b"), c.do("LRANGE", "r", "0", "-1"))
+	assert.Equal(t, "OK", c.do("LTRIM", "r", "1", "-1"))
+	assert.Equal(t, list("a", "b"), c.do("LRANGE", "r", "0", "-1"))
+
+	// empty lists are removed
+	c.do("RPOP", "r", "2")
+	assert.Equal(t, int64(0), c.do("EXISTS", "r"))
+	assert.Nil(t, c.do("LPOP", "r"))
+}
+
+func TestHashes(t *testing.T) {
+	c := connect(t, startServer(t))
+
+	assert.Equal(t, int64(2), c.do("HSET", "h", "name", "ds", "lang", "go"))
+	assert.Equal(t, int64(0), c.do("HSET", "h", "name", "ds2"))
+	assert.Equal(t, "ds2", c.do("HGET", "h", "name"))
+	assert.Nil(t, c.do("HGET", "h", "missing"))
+	assert.Equal(t, list("ds2", nil), c.do("HMGET", "h", "name", "missing"))
+	assert.Equal(t, int64(1), c.do("HEXISTS", "h", "lang"))
+	assert.Equal(t, int64(2), c.do("HLEN", "h"))
+	assert.Equal(t, int64(5), c.do("HINCRBY", "h", "count", "5"))
+	assert.Equal(t, respError("ERR hash value is not an integer"), c.do("HINCRBY", "h", "lang", "5"))
+
+	// fields keep their insertion order
+	assert.Equal(t, list("name", "lang", "count"), c.do("HKEYS", "h"))
+	assert.Equal(t, list("ds2", "go", "5"), c.do("HVALS", "h"))
+	assert.Equal(t, list("name", "ds2", "lang", "go", "count", "5"), c.do("HGETALL", "h"))
+
+	assert.Equal(t, int64(3), c.do("HDEL", "h", "name", "lang", "count"))
+	assert.Equal(t, int64(0), c.do("EXISTS", "h"))
+}
+
+func TestSets(t *testing.T) {
+	c := connect(t, startServer(t))
+
+	assert.Equal(t, int64(3), c.do("SADD", "s1", "a", "b", "c", "a"))
+	assert.Equal(t, int64(3), c.do("SADD", "s2", "b", "c", "d"))
+	assert.Equal(t, list("a", "b", "c"), c.do("SMEMBERS", "s1"))
+	assert.Equal(t, int64(1), c.do("SISMEMBER", "s1", "a"))
+	assert.Equal(t, int64(0), c.do("SISMEMBER", "s1", "d"))
+	assert.Equal(t, int64(3), c.do("SCARD", "s1"))
+	assert.Equal(t, list("b", "c"), c.do("SINTER", "s1", "s2"))
+	assert.Equal(t, list("a", "b", "c", "d"), c.do("SUNION", "s1", "s2"))
+	assert.Equal(t, list("a"), c.do("SDIFF", "s1", "s2"))
+
+	assert.Equal(t, int64(1), c.do("SREM", "s1", "a", "z"))
+	popped := c.do("SPOP", "s1", "5")
+	assert.ElementsMatch(t, list("b", "c"), popped)
+	assert.Equal(t, int64(0), c.do("EXISTS", "s1"))
+}
+
+func TestSortedSets(t *testing.T) {
+	c := connect(t, startServer(t))
+
+	assert.Equal(t, int64(3), c.do("ZADD", "z", "3", "c", "1", "a", "2", "b"))
+	assert.Equal(t, int64(1), c.do("ZADD", "z", "CH", "2", "b", "0", "d"))
+	assert.Equal(t, list("d", "a", "b", "c"), c.do("ZRANGE", "z", "0", "-1"))
+	assert.Equal(t, list("c", "3", "b", "2"), c.do("ZREVRANGE", "z", "0", "1", "WITHSCORES"))
+	assert.Equal(t, list("a", "b"), c.do("ZRANGEBYSCORE", "z", "(0", "2"))
+	assert.Equal(t, list("c", "b"), c.do("ZRANGE", "z", "+inf", "(1", "BYSCORE", "REV"))
+	assert.Equal(t, list("b"), c.do("ZRANGE", "z", "-inf", "+inf", "BYSCORE", "LIMIT", "2", "1"))
+	assert.Equal(t, int64(3), c.do("ZCOUNT", "z", "1", "+inf"))
+
+	assert.Equal(t, "2", c.do("ZSCORE", "z", "b"))
+	assert.Nil(t, c.do("ZSCORE", "z", "missing"))
+	assert.Equal(t, int64(2), c.do("ZRANK", "z", "b"))
+	assert.Equal(t, int64(1), c.do("ZREVRANK", "z", "b"))
+
+	// changing a score reorders the set
+	assert.Equal(t, "10", c.do("ZINCRBY", "z", "8", "b"))
+	assert.Equal(t, list("d", "a", "c", "b"), c.do("ZRANGE", "z", "0", "-1"))
+	assert.Equal(t, "12", c.do("ZADD", "z", "INCR", "2", "b"))
+	assert.Nil(t, c.do("ZADD", "z", "NX", "INCR", "2", "b"))
+
+	assert.Equal(t, list("d", "0"), c.do("ZPOPMIN", "z"))
+	assert.Equal(t, list("b", "12", "c", "3"), c.do("ZPOPMAX", "z", "2"))
+	assert.Equal(t, int64(1), c.do("ZREM", "z", "a"))
+	assert.Equal(t, int64(0), c.do("ZCARD", "z"))
+	assert.Equal(t, int64(0), c.do("EXISTS", "z"))
+}
+
+func TestRESP3(t *testing.T) {
+	c := connect(t, startServer(t))
+
+	hello := c.do("HELLO", "3")
+	require.IsType(t, map[string]any{}, hello)
+	assert.Equal(t, int64(3), hello.(map[string]any)["proto"])
+
+	assert.Nil(t, c.do("GET", "missing"))
+	c.do("HSET", "h", "a", "1", "b", "2")
+	assert.Equal(t, map[string]any{"a": "1", "b": "2"}, c.do("HGETALL", "h"))
+	c.do("ZADD", "z", "1.5", "a", "inf", "b")
+	assert.Equal(t, 1.5, c.do("ZSCORE", "z", "a"))
+	assert.Equal(t, list(list("a", 1.5), list("b", math.Inf(1))), c.do("ZRANGE", "z", "0", "-1", "WITHSCORES"))
+	assert.Equal(t, list("a", 1.5), c.do("ZPOPMIN", "z"))
+
+	assert.Equal(t, respError("NOPROTO unsupported protocol version"), c.do("HELLO", "4"))
+	c.do("HELLO", "2")
+	assert.Equal(t, list("a", "1", "b", "2"), c.do("HGETALL", "h"))
+}
+
+func TestMaxKeys(t *testing.T) {
+	c := connect(t, startServer(t, resp.WithMaxKeys(2)))
+
+	c.do("SET", "a", "1")
+	c.do("SET", "b", "2")
+	c.do("GET", "a") // b becomes the least recently used
+	c.do("SET", "c", "3")
+	assert.Equal(t, list("a", "c"), c.do("KEYS", "*"))
+}
+
+func TestZAddXXMissingKey(t *testing.T) {
+	c := connect(t, startServer(t, resp.WithMaxKeys(2)))
+
+	c.do("SET", "a", "1")
+	c.do("SET", "b", "2")
+	assert.Equal(t, int64(0), c.do("ZADD", "z", "XX", "1", "m"))
+	assert.Nil(t, c.do("ZADD", "z", "XX", "INCR", "1", "m"))
+	assert.Equal(t, int64(0), c.do("EXISTS", "z"))
+	// creating and deleting z would have evicted a
+	assert.Equal(t, list("a", "b"), c.do("KEYS", "*"))
+}
+
+func TestConcurrentClients(t *testing.T) {
+	addr := startServer(t)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		c := connect(t, addr)
+		wg.Go(func() {
+			for range 100 {
+				c.do("INCR", "counter")
+			}
+		})
+	}
+	wg.Wait()
+
+	assert.Equal(t, "1000", connect(t, addr).do("GET", "counter"))
+}
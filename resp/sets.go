@@ -0,0 +1,213 @@
+package resp
+
+import (
+	"slices"
+
+	"github.com/quintans/ds/collections/set"
+)
+
+func newSet() *set.Set[string, string] {
+	return set.New[string]()
+}
+
+// sortedMembers returns the members of a set in lexicographic order, so that replies are deterministic
+func sortedMembers(st *set.Set[string, string]) []string {
+	return slices.Sorted(st.Values())
+}
+
+func writeSet(c *client, members []string) {
+	c.w.setHeader(len(members))
+	for _, m := range members {
+		c.w.bulk(m)
+	}
+}
+
+func cmdSAdd(s *Server, c *client, args []string) error {
+	st, err := lookupOrCreate(s.ks, args[1], newSet)
+	if err != nil {
+		return err
+	}
+	var added int64
+	for _, m := range args[2:] {
+		if !st.Contains(m) {
+			st.Add(m)
+			added++
+		}
+	}
+	c.w.integer(added)
+	return nil
+}
+
+func cmdSRem(s *Server, c *client, args []string) error {
+	st, ok, err := lookup[*set.Set[string, string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.integer(0)
+		return nil
+	}
+	var removed int64
+	for _, m := range args[2:] {
+		if st.Delete(m) {
+			removed++
+		}
+	}
+	s.ks.deleteIfEmpty(args[1], st.Size())
+	c.w.integer(removed)
+	return nil
+}
+
+func cmdSMembers(s *Server, c *client, args []string) error {
+	st, ok, err := lookup[*set.Set[string, string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		writeSet(c, nil)
+		return nil
+	}
+	writeSet(c, sortedMembers(st))
+	return nil
+}
+
+func cmdSIsMember(s *Server, c *client, args []string) error {
+	st, ok, err := lookup[*set.Set[string, string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if ok && st.Contains(args[2]) {
+		c.w.integer(1)
+	} else {
+		c.w.integer(0)
+	}
+	return nil
+}
+
+func cmdSCard(s *Server, c *client, args []string) error {
+	st, ok, err := lookup[*set.Set[string, string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.integer(0)
+		return nil
+	}
+	c.w.integer(int64(st.Size()))
+	return nil
+}
+
+// cmdSPop implements SPOP key [count], removing arbitrary members
+func cmdSPop(s *Server, c *client, args []string) error {
+	if len(args) > 3 {
+		return errSyntax
+	}
+	count := int64(-1)
+	if len(args) == 3 {
+		n, err := parseInt(args[2])
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return errNotPositive
+		}
+		count = n
+	}
+
+	st, ok, err := lookup[*set.Set[string, string]](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if count < 0 {
+			c.w.null()
+		} else {
+			writeSet(c, nil)
+		}
+		return nil
+	}
+
+	limit := count
+	if limit < 0 {
+		limit = 1
+	}
+	var popped []string
+	for m := range st.Values() {
+		if int64(len(popped)) == limit {
+			break
+		}
+		popped = append(popped, m)
+	}
+	for _, m := range popped {
+		st.Delete(m)
+	}
+	s.ks.deleteIfEmpty(args[1], st.Size())
+
+	if count < 0 {
+		c.w.bulk(popped[0])
+		return nil
+	}
+	writeSet(c, popped)
+	return nil
+}
+
+// sets returns the sets stored in the keys. Missing keys are returned as empty sets.
+func sets(s *Server, keys []string) ([]*set.Set[string, string], error) {
+	result := make([]*set.Set[string, string], 0, len(keys))
+	for _, key := range keys {
+		st, ok, err := lookup[*set.Set[string, string]](s.ks, key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			st = newSet()
+		}
+		result = append(result, st)
+	}
+	return result, nil
+}
+
+func cmdSInter(s *Server, c *client, args []string) error {
+	all, err := sets(s, args[1:])
+	if err != nil {
+		return err
+	}
+	var members []string
+	for m := range all[0].Values() {
+		if !slices.ContainsFunc(all[1:], func(st *set.Set[string, string]) bool { return !st.Contains(m) }) {
+			members = append(members, m)
+		}
+	}
+	slices.Sort(members)
+	writeSet(c, members)
+	return nil
+}
+
+func cmdSUnion(s *Server, c *client, args []string) error {
+	all, err := sets(s, args[1:])
+	if err != nil {
+		return err
+	}
+	union := newSet()
+	for _, st := range all {
+		union.Add(slices.Collect(st.Values())...)
+	}
+	writeSet(c, sortedMembers(union))
+	return nil
+}
+
+func cmdSDiff(s *Server, c *client, args []string) error {
+	all, err := sets(s, args[1:])
+	if err != nil {
+		return err
+	}
+	var members []string
+	for m := range all[0].Values() {
+		if !slices.ContainsFunc(all[1:], func(st *set.Set[string, string]) bool { return st.Contains(m) }) {
+			members = append(members, m)
+		}
+	}
+	slices.Sort(members)
+	writeSet(c, members)
+	return nil
+}
@@ -0,0 +1,495 @@
+package resp
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var errMinMaxNotFloat = errors.New("ERR min or max is not a float")
+
+// scoreBound is one end of a score interval, as accepted by ZRANGEBYSCORE and ZCOUNT
+type scoreBound struct {
+	value     float64
+	exclusive bool
+}
+
+func parseScoreBound(s string) (scoreBound, error) {
+	var b scoreBound
+	if strings.HasPrefix(s, "(") {
+		b.exclusive = true
+		s = s[1:]
+	}
+	f, err := parseFloat(s)
+	if err != nil {
+		return b, errMinMaxNotFloat
+	}
+	b.value = f
+	return b, nil
+}
+
+func (b scoreBound) belowOrEqual(score float64) bool {
+	if b.exclusive {
+		return b.value < score
+	}
+	return b.value <= score
+}
+
+func (b scoreBound) aboveOrEqual(score float64) bool {
+	if b.exclusive {
+		return b.value > score
+	}
+	return b.value >= score
+}
+
+func byScore(members []member, lo, hi scoreBound) []member {
+	var result []member
+	for _, m := range members {
+		if lo.belowOrEqual(m.score) && hi.aboveOrEqual(m.score) {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+func writeMembers(c *client, members []member, withScores bool) {
+	switch {
+	case !withScores:
+		c.w.array(len(members))
+		for _, m := range members {
+			c.w.bulk(m.name)
+		}
+	case c.w.proto == 3:
+		c.w.array(len(members))
+		for _, m := range members {
+			c.w.array(2)
+			c.w.bulk(m.name)
+			c.w.double(m.score)
+		}
+	default:
+		c.w.array(len(members) * 2)
+		for _, m := range members {
+			c.w.bulk(m.name)
+			c.w.double(m.score)
+		}
+	}
+}
+
+// cmdZAdd implements ZADD key [NX | XX] [CH] [INCR] score member [score member ...]
+func cmdZAdd(s *Server, c *client, args []string) error {
+	var nx, xx, ch, incr bool
+	i := 2
+loop:
+	for ; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "CH":
+			ch = true
+		case "INCR":
+			incr = true
+		default:
+			break loop
+		}
+	}
+	pairs := args[i:]
+	if len(pairs) == 0 || len(pairs)%2 != 0 || (nx && xx) || (incr && len(pairs) != 2) {
+		return errSyntax
+	}
+
+	scores := make([]float64, 0, len(pairs)/2)
+	for j := 0; j < len(pairs); j += 2 {
+		f, err := parseFloat(pairs[j])
+		if err != nil {
+			return err
+		}
+		scores = append(scores, f)
+	}
+
+	z, ok, err := lookup[*zset](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// with XX nothing is added to a missing key, so it is not created
+		if xx {
+			if incr {
+				c.w.null()
+			} else {
+				c.w.integer(0)
+			}
+			return nil
+		}
+		z = newZSet()
+		s.ks.put(args[1], z)
+	}
+
+	var added, changed int64
+	var result float64
+	var aborted bool
+	for j, score := range scores {
+		name := pairs[j*2+1]
+		old, exists := z.score(name)
+		if (nx && exists) || (xx && !exists) {
+			aborted = true
+			continue
+		}
+		if incr {
+			score += old
+			if math.IsNaN(score) {
+				s.ks.deleteIfEmpty(args[1], z.size())
+				return errors.New("ERR resulting score is not a number (NaN)")
+			}
+		}
+		result = score
+		if z.add(name, score) {
+			added++
+		} else if old != score {
+			changed++
+		}
+	}
+	s.ks.deleteIfEmpty(args[1], z.size())
+
+	if incr {
+		if aborted {
+			c.w.null()
+		} else {
+			c.w.double(result)
+		}
+		return nil
+	}
+	if ch {
+		c.w.integer(added + changed)
+	} else {
+		c.w.integer(added)
+	}
+	return nil
+}
+
+func cmdZIncrBy(s *Server, c *client, args []string) error {
+	delta, err := parseFloat(args[2])
+	if err != nil {
+		return err
+	}
+	z, err := lookupOrCreate(s.ks, args[1], newZSet)
+	if err != nil {
+		return err
+	}
+	old, _ := z.score(args[3])
+	score := old + delta
+	if math.IsNaN(score) {
+		s.ks.deleteIfEmpty(args[1], z.size())
+		return errors.New("ERR resulting score is not a number (NaN)")
+	}
+	z.add(args[3], score)
+	c.w.double(score)
+	return nil
+}
+
+func cmdZScore(s *Server, c *client, args []string) error {
+	z, ok, err := lookup[*zset](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.null()
+		return nil
+	}
+	score, ok := z.score(args[2])
+	if !ok {
+		c.w.null()
+		return nil
+	}
+	c.w.double(score)
+	return nil
+}
+
+func cmdZRem(s *Server, c *client, args []string) error {
+	z, ok, err := lookup[*zset](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.integer(0)
+		return nil
+	}
+	var removed int64
+	for _, name := range args[2:] {
+		if z.remove(name) {
+			removed++
+		}
+	}
+	s.ks.deleteIfEmpty(args[1], z.size())
+	c.w.integer(removed)
+	return nil
+}
+
+func cmdZCard(s *Server, c *client, args []string) error {
+	z, ok, err := lookup[*zset](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.integer(0)
+		return nil
+	}
+	c.w.integer(int64(z.size()))
+	return nil
+}
+
+func cmdZCount(s *Server, c *client, args []string) error {
+	lo, err := parseScoreBound(args[2])
+	if err != nil {
+		return err
+	}
+	hi, err := parseScoreBound(args[3])
+	if err != nil {
+		return err
+	}
+	z, ok, err := lookup[*zset](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.integer(0)
+		return nil
+	}
+	var count int64
+	for m := range z.pq.Values() {
+		if lo.belowOrEqual(m.score) && hi.aboveOrEqual(m.score) {
+			count++
+		}
+	}
+	c.w.integer(count)
+	return nil
+}
+
+func rank(s *Server, c *client, args []string, reverse bool) error {
+	z, ok, err := lookup[*zset](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.null()
+		return nil
+	}
+	r, ok := z.rank(args[2])
+	if !ok {
+		c.w.null()
+		return nil
+	}
+	if reverse {
+		r = z.size() - 1 - r
+	}
+	c.w.integer(int64(r))
+	return nil
+}
+
+func cmdZRank(s *Server, c *client, args []string) error {
+	return rank(s, c, args, false)
+}
+
+func cmdZRevRank(s *Server, c *client, args []string) error {
+	return rank(s, c, args, true)
+}
+
+type rangeSpec struct {
+	byScore    bool
+	reverse    bool
+	withScores bool
+	limit      bool
+	offset     int64
+	count      int64
+}
+
+// zrange is shared by ZRANGE, ZREVRANGE and ZRANGEBYSCORE.
+// For index ranges start and stop are ranks, for score ranges they are the min and max scores,
+// swapped when reversed, as in Redis.
+func zrange(s *Server, c *client, key, start, stop string, spec rangeSpec) error {
+	var members []member
+	if spec.byScore {
+		lo, err := parseScoreBound(start)
+		if err != nil {
+			return err
+		}
+		hi, err := parseScoreBound(stop)
+		if err != nil {
+			return err
+		}
+		if spec.reverse {
+			lo, hi = hi, lo
+		}
+		z, ok, err := lookup[*zset](s.ks, key)
+		if err != nil {
+			return err
+		}
+		if ok {
+			members = byScore(z.sorted(), lo, hi)
+		}
+		if spec.reverse {
+			slices.Reverse(members)
+		}
+		if spec.limit {
+			if spec.offset < 0 || spec.offset >= int64(len(members)) {
+				members = nil
+			} else {
+				members = members[spec.offset:]
+				if spec.count >= 0 && spec.count < int64(len(members)) {
+					members = members[:spec.count]
+				}
+			}
+		}
+	} else {
+		if spec.limit {
+			return errors.New("ERR syntax error, LIMIT is only supported in combination with either BYSCORE or BYLEX")
+		}
+		from, err := parseInt(start)
+		if err != nil {
+			return err
+		}
+		to, err := parseInt(stop)
+		if err != nil {
+			return err
+		}
+		z, ok, err := lookup[*zset](s.ks, key)
+		if err != nil {
+			return err
+		}
+		if ok {
+			members = z.sorted()
+			if spec.reverse {
+				slices.Reverse(members)
+			}
+			if i, j, ok := normalizeRange(from, to, len(members)); ok {
+				members = members[i:j]
+			} else {
+				members = nil
+			}
+		}
+	}
+	writeMembers(c, members, spec.withScores)
+	return nil
+}
+
+// parseRangeOptions parses [BYSCORE] [REV] [LIMIT offset count] [WITHSCORES].
+// allowed filters the accepted options for the legacy commands.
+func parseRangeOptions(args []string, allowed ...string) (rangeSpec, error) {
+	spec := rangeSpec{count: -1}
+	for i := 0; i < len(args); i++ {
+		opt := strings.ToUpper(args[i])
+		if !slices.Contains(allowed, opt) {
+			return spec, errSyntax
+		}
+		switch opt {
+		case "BYSCORE":
+			spec.byScore = true
+		case "REV":
+			spec.reverse = true
+		case "WITHSCORES":
+			spec.withScores = true
+		case "LIMIT":
+			if i+2 >= len(args) {
+				return spec, errSyntax
+			}
+			offset, err := parseInt(args[i+1])
+			if err != nil {
+				return spec, err
+			}
+			count, err := parseInt(args[i+2])
+			if err != nil {
+				return spec, err
+			}
+			spec.limit = true
+			spec.offset = offset
+			spec.count = count
+			i += 2
+		}
+	}
+	return spec, nil
+}
+
+func cmdZRange(s *Server, c *client, args []string) error {
+	spec, err := parseRangeOptions(args[4:], "BYSCORE", "REV", "LIMIT", "WITHSCORES")
+	if err != nil {
+		return err
+	}
+	return zrange(s, c, args[1], args[2], args[3], spec)
+}
+
+func cmdZRevRange(s *Server, c *client, args []string) error {
+	spec, err := parseRangeOptions(args[4:], "WITHSCORES")
+	if err != nil {
+		return err
+	}
+	spec.reverse = true
+	return zrange(s, c, args[1], args[2], args[3], spec)
+}
+
+func cmdZRangeByScore(s *Server, c *client, args []string) error {
+	spec, err := parseRangeOptions(args[4:], "LIMIT", "WITHSCORES")
+	if err != nil {
+		return err
+	}
+	spec.byScore = true
+	return zrange(s, c, args[1], args[2], args[3], spec)
+}
+
+// popMembers implements ZPOPMIN and ZPOPMAX key [count]
+func popMembers(s *Server, c *client, args []string, pop func(z *zset) member) error {
+	if len(args) > 3 {
+		return errSyntax
+	}
+	count := int64(1)
+	hasCount := len(args) == 3
+	if hasCount {
+		n, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil || n < 0 {
+			return errNotPositive
+		}
+		count = n
+	}
+
+	z, ok, err := lookup[*zset](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	var popped []member
+	if ok {
+		for range min(count, int64(z.size())) {
+			popped = append(popped, pop(z))
+		}
+		s.ks.deleteIfEmpty(args[1], z.size())
+	}
+
+	// without count, RESP3 replies with a flat member/score pair
+	if !hasCount && c.w.proto == 3 {
+		if len(popped) == 0 {
+			c.w.array(0)
+			return nil
+		}
+		c.w.array(2)
+		c.w.bulk(popped[0].name)
+		c.w.double(popped[0].score)
+		return nil
+	}
+	writeMembers(c, popped, true)
+	return nil
+}
+
+func cmdZPopMin(s *Server, c *client, args []string) error {
+	return popMembers(s, c, args, func(z *zset) member {
+		m, _ := z.popMin()
+		return m
+	})
+}
+
+func cmdZPopMax(s *Server, c *client, args []string) error {
+	return popMembers(s, c, args, func(z *zset) member {
+		m, _ := z.popMax()
+		return m
+	})
+}
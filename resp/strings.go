@@ -0,0 +1,317 @@
+package resp
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func cmdGet(s *Server, c *client, args []string) error {
+	v, ok, err := lookup[string](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.null()
+		return nil
+	}
+	c.w.bulk(v)
+	return nil
+}
+
+// cmdSet implements SET key value [NX | XX] [GET] [EX seconds | PX milliseconds | EXAT unix-time-seconds | PXAT unix-time-milliseconds | KEEPTTL]
+func cmdSet(s *Server, c *client, args []string) error {
+	key, value := args[1], args[2]
+	var nx, xx, get, keepTTL bool
+	var expireAt time.Time
+	for i := 3; i < len(args); i++ {
+		opt := strings.ToUpper(args[i])
+		switch opt {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "GET":
+			get = true
+		case "KEEPTTL":
+			keepTTL = true
+		case "EX", "PX", "EXAT", "PXAT":
+			if i+1 >= len(args) || !expireAt.IsZero() {
+				return errSyntax
+			}
+			i++
+			n, err := parseInt(args[i])
+			if err != nil {
+				return err
+			}
+			if n <= 0 {
+				return errInvalidExpireTime(args[0])
+			}
+			switch opt {
+			case "EX", "PX":
+				unit := time.Second
+				if opt == "PX" {
+					unit = time.Millisecond
+				}
+				d, err := parseDuration(args[0], n, unit)
+				if err != nil {
+					return err
+				}
+				expireAt = s.now().Add(d)
+			case "EXAT":
+				expireAt = time.Unix(n, 0)
+			case "PXAT":
+				expireAt = time.UnixMilli(n)
+			}
+		default:
+			return errSyntax
+		}
+	}
+	if (nx && xx) || (keepTTL && !expireAt.IsZero()) {
+		return errSyntax
+	}
+
+	old, exists := s.ks.get(key)
+	var oldValue string
+	if get && exists {
+		v, ok := old.data.(string)
+		if !ok {
+			return errWrongType
+		}
+		oldValue = v
+	}
+
+	reply := func() {
+		if !get {
+			c.w.ok()
+			return
+		}
+		if exists {
+			c.w.bulk(oldValue)
+		} else {
+			c.w.null()
+		}
+	}
+
+	if (nx && exists) || (xx && !exists) {
+		if get {
+			reply()
+		} else {
+			c.w.null()
+		}
+		return nil
+	}
+
+	e := s.ks.put(key, value)
+	if keepTTL && exists {
+		e.expireAt = old.expireAt
+	} else {
+		e.expireAt = expireAt
+	}
+	reply()
+	return nil
+}
+
+func cmdSetNX(s *Server, c *client, args []string) error {
+	if _, ok := s.ks.get(args[1]); ok {
+		c.w.integer(0)
+		return nil
+	}
+	s.ks.put(args[1], args[2])
+	c.w.integer(1)
+	return nil
+}
+
+func setWithTTL(s *Server, c *client, args []string, unit time.Duration) error {
+	n, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	if n <= 0 {
+		return errInvalidExpireTime(args[0])
+	}
+	d, err := parseDuration(args[0], n, unit)
+	if err != nil {
+		return err
+	}
+	e := s.ks.put(args[1], args[3])
+	e.expireAt = s.now().Add(d)
+	c.w.ok()
+	return nil
+}
+
+func cmdSetEX(s *Server, c *client, args []string) error {
+	return setWithTTL(s, c, args, time.Second)
+}
+
+func cmdPSetEX(s *Server, c *client, args []string) error {
+	return setWithTTL(s, c, args, time.Millisecond)
+}
+
+func cmdGetDel(s *Server, c *client, args []string) error {
+	v, ok, err := lookup[string](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.w.null()
+		return nil
+	}
+	s.ks.delete(args[1])
+	c.w.bulk(v)
+	return nil
+}
+
+func cmdMGet(s *Server, c *client, args []string) error {
+	c.w.array(len(args) - 1)
+	for _, key := range args[1:] {
+		// keys holding other types are reported as missing
+		v, ok, err := lookup[string](s.ks, key)
+		if err != nil || !ok {
+			c.w.null()
+			continue
+		}
+		c.w.bulk(v)
+	}
+	return nil
+}
+
+func cmdMSet(s *Server, c *client, args []string) error {
+	if len(args)%2 == 0 {
+		return errWrongArgs(args[0])
+	}
+	for i := 1; i < len(args); i += 2 {
+		s.ks.put(args[i], args[i+1])
+	}
+	c.w.ok()
+	return nil
+}
+
+// update replaces the string stored at key keeping its TTL, creating the key if it does not exist.
+// fn is told whether the key exists, since an existing empty string is not the same as a missing key.
+func update(s *Server, key string, fn func(old string, exists bool) (string, error)) error {
+	e, ok := s.ks.get(key)
+	if !ok {
+		v, err := fn("", false)
+		if err != nil {
+			return err
+		}
+		s.ks.put(key, v)
+		return nil
+	}
+	old, ok := e.data.(string)
+	if !ok {
+		return errWrongType
+	}
+	v, err := fn(old, true)
+	if err != nil {
+		return err
+	}
+	e.data = v
+	return nil
+}
+
+func incrBy(s *Server, c *client, key string, delta int64) error {
+	var result int64
+	err := update(s, key, func(old string, exists bool) (string, error) {
+		var n int64
+		if exists {
+			var err error
+			n, err = parseInt(old)
+			if err != nil {
+				return "", err
+			}
+		}
+		if (delta > 0 && n > math.MaxInt64-delta) || (delta < 0 && n < math.MinInt64-delta) {
+			return "", errOverflow
+		}
+		result = n + delta
+		return strconv.FormatInt(result, 10), nil
+	})
+	if err != nil {
+		return err
+	}
+	c.w.integer(result)
+	return nil
+}
+
+func cmdIncr(s *Server, c *client, args []string) error {
+	return incrBy(s, c, args[1], 1)
+}
+
+func cmdDecr(s *Server, c *client, args []string) error {
+	return incrBy(s, c, args[1], -1)
+}
+
+func cmdIncrBy(s *Server, c *client, args []string) error {
+	delta, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	return incrBy(s, c, args[1], delta)
+}
+
+func cmdDecrBy(s *Server, c *client, args []string) error {
+	delta, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	if delta == math.MinInt64 {
+		return errOverflow
+	}
+	return incrBy(s, c, args[1], -delta)
+}
+
+func cmdIncrByFloat(s *Server, c *client, args []string) error {
+	delta, err := parseFloat(args[2])
+	if err != nil {
+		return err
+	}
+	var result string
+	err = update(s, args[1], func(old string, exists bool) (string, error) {
+		var n float64
+		if exists {
+			var err error
+			n, err = parseFloat(old)
+			if err != nil {
+				return "", err
+			}
+		}
+		f := n + delta
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return "", errors.New("ERR increment would produce NaN or Infinity")
+		}
+		result = formatFloat(f)
+		return result, nil
+	})
+	if err != nil {
+		return err
+	}
+	c.w.bulk(result)
+	return nil
+}
+
+func cmdAppend(s *Server, c *client, args []string) error {
+	var size int
+	err := update(s, args[1], func(old string, _ bool) (string, error) {
+		v := old + args[2]
+		size = len(v)
+		return v, nil
+	})
+	if err != nil {
+		return err
+	}
+	c.w.integer(int64(size))
+	return nil
+}
+
+func cmdStrLen(s *Server, c *client, args []string) error {
+	v, _, err := lookup[string](s.ks, args[1])
+	if err != nil {
+		return err
+	}
+	c.w.integer(int64(len(v)))
+	return nil
+}
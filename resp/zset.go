@@ -0,0 +1,99 @@
+package resp
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/quintans/ds/collections/indexedpriorityqueue"
+)
+
+type member struct {
+	name  string
+	score float64
+}
+
+// compareMembers orders members by score and then lexicographically by name, like Redis does
+func compareMembers(a, b member) int {
+	if c := cmp.Compare(a.score, b.score); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.name, b.name)
+}
+
+// zset is a sorted set backed by an indexed priority queue keyed by member name.
+// The lowest score is always at the head of the queue.
+type zset struct {
+	pq *indexedpriorityqueue.IndexedPriorityQueue[member, string]
+}
+
+func newZSet() *zset {
+	return &zset{
+		pq: indexedpriorityqueue.New(compareMembers, func(m member) string {
+			return m.name
+		}),
+	}
+}
+
+func (z *zset) size() int {
+	return z.pq.Len()
+}
+
+func (z *zset) score(name string) (float64, bool) {
+	m, ok := z.pq.Get(name)
+	return m.score, ok
+}
+
+// add sets the score of a member, returning true if the member is new
+func (z *zset) add(name string, score float64) bool {
+	_, exists := z.pq.Get(name)
+	z.pq.Enqueue(member{name: name, score: score})
+	return !exists
+}
+
+func (z *zset) remove(name string) bool {
+	_, ok := z.pq.Remove(name)
+	return ok
+}
+
+// popMin removes the member with the lowest score, O(log n)
+func (z *zset) popMin() (member, bool) {
+	return z.pq.Dequeue()
+}
+
+// sorted returns all members in ascending order
+func (z *zset) sorted() []member {
+	members := slices.Collect(z.pq.Values())
+	slices.SortFunc(members, compareMembers)
+	return members
+}
+
+// rank returns the 0 based position of a member in ascending order
+func (z *zset) rank(name string) (int, bool) {
+	m, ok := z.pq.Get(name)
+	if !ok {
+		return 0, false
+	}
+	rank := 0
+	for other := range z.pq.Values() {
+		if compareMembers(other, m) < 0 {
+			rank++
+		}
+	}
+	return rank, true
+}
+
+// popMax removes the member with the highest score, O(n)
+func (z *zset) popMax() (member, bool) {
+	var highest member
+	found := false
+	for m := range z.pq.Values() {
+		if !found || compareMembers(m, highest) > 0 {
+			highest = m
+			found = true
+		}
+	}
+	if found {
+		z.pq.Remove(highest.name)
+	}
+	return highest, found
+}